@@ -0,0 +1,133 @@
+package assertion
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/utils"
+)
+
+// Issue is a problem found in a check without running it. Key is the check
+// key it belongs to, empty for the check as a whole.
+type Issue struct {
+	Key     string
+	Message string
+}
+
+var lenPrefixRE = regexp.MustCompile(`(?i)^len(\s|[<>=!]|$)`)
+
+// ValidatePath reports whether path is a JSONPath this package can evaluate.
+func ValidatePath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// Lint finds invalid JSONPaths, regexes that do not compile and malformed
+// `len` expressions in a check. Values containing placeholders are skipped
+// because they are only known at run time.
+func Lint(check any) []Issue {
+	var issues []Issue
+	add := func(key string, format string, args ...any) {
+		issues = append(issues, Issue{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch t := check.(type) {
+	case nil, int, int64, float64:
+	case string:
+		if _, err := strconv.Atoi(strings.TrimSpace(t)); err != nil && !strings.Contains(t, "${") {
+			add("", "check string must be a status code or map, got %q", t)
+		}
+	case map[string]any:
+		for _, key := range sortedKeys(t) {
+			expected := t[key]
+			switch {
+			case key == "status":
+				if utils.ToInt(expected, -1) < 0 && !strings.Contains(utils.ToString(expected), "${") {
+					add(key, "status must be a number, got %q", utils.ToString(expected))
+				}
+			case key == "headers":
+				headers := utils.ToStringMap(expected)
+				for _, name := range sortedKeys(headers) {
+					if msg := lintExpectation(headers[name]); msg != "" {
+						add(key+"."+name, "%s", msg)
+					}
+				}
+			case key == "body":
+				lintBody(key, expected, add)
+			case strings.HasPrefix(key, "$"):
+				lintPath(key, key, expected, add)
+			}
+		}
+	default:
+		add("", "unsupported check type %T", check)
+	}
+	return issues
+}
+
+func lintBody(key string, raw any, add func(string, string, ...any)) {
+	switch t := raw.(type) {
+	case map[string]any:
+		for _, path := range sortedKeys(t) {
+			lintPath(key+"."+path, path, t[path], add)
+		}
+	case []any:
+		for i, row := range t {
+			item := utils.ToStringMap(row)
+			itemKey := fmt.Sprintf("%s[%d]", key, i)
+			path := utils.ToString(item["path"])
+			if strings.TrimSpace(path) == "" {
+				add(itemKey, "body check list item requires path")
+				continue
+			}
+			if op := strings.ToLower(utils.ToString(item["operator"])); op != "" && op != "eq" {
+				add(itemKey+".operator", "unsupported body operator %q", op)
+			}
+			lintPath(itemKey+".path", path, item["value"], add)
+		}
+	default:
+		add(key, "body checks must be map or list, got %T", raw)
+	}
+}
+
+func lintPath(key string, path string, expected any, add func(string, string, ...any)) {
+	if err := ValidatePath(path); err != nil {
+		add(key, "%v", err)
+		return
+	}
+	if msg := lintExpectation(expected); msg != "" {
+		add(key, "%s", msg)
+	}
+}
+
+func lintExpectation(expected any) string {
+	text, ok := expected.(string)
+	if !ok || strings.Contains(text, "${") {
+		return ""
+	}
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "/") && strings.HasSuffix(trimmed, "/") && len(trimmed) > 2 {
+		pattern := trimmed[1 : len(trimmed)-1]
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Sprintf("invalid regex %q: %v", pattern, err)
+		}
+		return ""
+	}
+	if lenPrefixRE.MatchString(trimmed) {
+		if _, ok := parseLenExpr(trimmed); !ok {
+			return fmt.Sprintf("malformed len expression %q (expected e.g. \"len >= 1\")", trimmed)
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
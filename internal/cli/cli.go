@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -14,13 +17,16 @@ import (
 	"time"
 
 	"github.com/DevrajJain04/reqres/internal/config"
+	"github.com/DevrajJain04/reqres/internal/events"
 	"github.com/DevrajJain04/reqres/internal/gha"
+	"github.com/DevrajJain04/reqres/internal/history"
 	"github.com/DevrajJain04/reqres/internal/loadtest"
 	"github.com/DevrajJain04/reqres/internal/mockserver"
 	"github.com/DevrajJain04/reqres/internal/model"
 	"github.com/DevrajJain04/reqres/internal/openapi"
 	"github.com/DevrajJain04/reqres/internal/report"
 	"github.com/DevrajJain04/reqres/internal/runner"
+	"github.com/DevrajJain04/reqres/internal/secrets"
 	"github.com/DevrajJain04/reqres/internal/snapshot"
 	"github.com/DevrajJain04/reqres/internal/utils"
 )
@@ -32,7 +38,9 @@ func Run(args []string) int {
 	}
 	switch args[0] {
 	case "run":
-		return runCommand(args[1:])
+		ctx, stop := interruptContext()
+		defer stop()
+		return runCommand(ctx, args[1:])
 	case "validate":
 		return validateCommand(args[1:])
 	case "mock":
@@ -41,6 +49,16 @@ func Run(args []string) int {
 		return generateCommand(args[1:])
 	case "gha-init":
 		return ghaInitCommand(args[1:])
+	case "schema":
+		return schemaCommand(args[1:])
+	case "fmt":
+		return fmtCommand(args[1:])
+	case "list":
+		return listCommand(args[1:])
+	case "report":
+		return reportCommand(args[1:])
+	case "history":
+		return historyCommand(args[1:])
 	case "help", "-h", "--help":
 		printUsage()
 		return 0
@@ -51,36 +69,88 @@ func Run(args []string) int {
 	}
 }
 
-func runCommand(args []string) int {
+func runCommand(ctx context.Context, args []string) int {
 	fs := flag.NewFlagSet("run", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 
 	tagsRaw := fs.String("tags", "", "comma-separated tags to include")
 	env := fs.String("env", "", "environment override name")
+	envFile := fs.String("env-file", "", "read variables from this file instead of the .env next to the suite")
+	cliVars := varFlags{}
+	fs.Var(cliVars, "var", "set a variable as key=value (repeatable)")
 	parallel := fs.Int("parallel", max(1, runtime.NumCPU()), "parallel workers")
 	reportJSON := fs.String("report-json", "", "write JSON report to this path")
 	reportHTML := fs.String("report-html", "", "write HTML report to this path")
+	reportMD := fs.String("report-markdown", "", "write Markdown summary to this path")
 	ghaFlag := fs.Bool("github-actions", false, "emit GitHub Actions annotations")
 	flakyRuns := fs.Int("detect-flaky", 1, "rerun suites to detect flaky tests")
 	updateSnapshots := fs.Bool("update-snapshots", false, "rewrite snapshot baselines")
 	noLoad := fs.Bool("no-load", false, "skip load block execution")
+	historyPath := fs.String("history", "", "append results to this run history file")
+	flakyThreshold := fs.Float64("flaky-threshold", history.DefaultThreshold, "history flakiness score that labels a test flaky")
+	format := fs.String("format", "text", "console output: text or ndjson (one JSON event per line)")
+	seed := fs.Int64("seed", 0, "seed for uuid(), randInt() and randString()")
+	strict := fs.Bool("strict", false, "treat unknown keys as errors")
+	failFast := fs.Bool("fail-fast", false, "stop the run at the first failing test")
+	maxFailures := fs.Int("max-failures", 0, "stop the run after this many failing tests")
+	timeout := fs.Duration("timeout", 0, "fail whatever is still running after this long (e.g. 10m)")
+	rerunFailed := fs.String("rerun-failed", "", "run only the tests that failed in this JSON report")
+	watchFlag := fs.Bool("watch", false, "rerun changed tests whenever the suites, their includes or data files change")
+	dryRunFormat := fs.String("dry-run", "", "print the requests as curl commands (or http blocks) instead of sending them")
 
+	// Bare flags get their default before reordering so a following suite
+	// file is not mistaken for the flag value.
+	args = fillDefaultForBareFlag(args, "--parallel", strconv.Itoa(max(1, runtime.NumCPU())))
+	args = fillDefaultForBareFlag(args, "--history", history.DefaultPath)
+	args = fillDefaultForBareFlag(args, "--dry-run", "curl")
 	normalizedArgs := reorderArgs(args, map[string]bool{
 		"--tags":             true,
 		"--env":              true,
+		"--env-file":         true,
+		"--var":              true,
 		"--parallel":         true,
 		"--report-json":      true,
 		"--report-html":      true,
+		"--report-markdown":  true,
 		"--detect-flaky":     true,
+		"--history":          true,
+		"--flaky-threshold":  true,
+		"--format":           true,
+		"--seed":             true,
+		"--dry-run":          true,
+		"--rerun-failed":     true,
+		"--max-failures":     true,
+		"--timeout":          true,
+		"--fail-fast":        false,
 		"--github-actions":   false,
 		"--update-snapshots": false,
 		"--no-load":          false,
+		"--strict":           false,
+		"--watch":            false,
 	})
-	normalizedArgs = fillDefaultForBareFlag(normalizedArgs, "--parallel", strconv.Itoa(max(1, runtime.NumCPU())))
 	if err := fs.Parse(normalizedArgs); err != nil {
 		return 1
 	}
 	files := fs.Args()
+	var only map[string][]string
+	if path := strings.TrimSpace(*rerunFailed); path != "" {
+		previous, err := report.ReadJSON(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		only = report.FailedTests(previous)
+		if len(only) == 0 {
+			fmt.Printf("No failures in %s, nothing to rerun\n", path)
+			return 0
+		}
+		if len(files) == 0 {
+			files = failedSuiteFiles(previous)
+		} else if !failuresIn(only, files) {
+			fmt.Fprintf(os.Stderr, "%s has failures, but none in %s\n", path, strings.Join(files, ", "))
+			return 1
+		}
+	}
 	if len(files) == 0 {
 		fmt.Fprintln(os.Stderr, "run requires at least one yaml file")
 		return 1
@@ -88,25 +158,91 @@ func runCommand(args []string) int {
 
 	opts := model.RunOptions{
 		Env:             strings.TrimSpace(*env),
+		EnvFile:         strings.TrimSpace(*envFile),
+		Vars:            cliVars,
+		Strict:          *strict,
 		Tags:            parseCSV(*tagsRaw),
 		Parallel:        max(1, *parallel),
 		ReportJSONPath:  strings.TrimSpace(*reportJSON),
 		ReportHTMLPath:  strings.TrimSpace(*reportHTML),
+		ReportMDPath:    strings.TrimSpace(*reportMD),
 		GitHubActions:   gha.Enabled(*ghaFlag),
 		DetectFlakyRuns: max(1, *flakyRuns),
 		UpdateSnapshots: *updateSnapshots,
 		RunLoad:         !*noLoad,
+		HistoryPath:     strings.TrimSpace(*historyPath),
+		FlakyThreshold:  *flakyThreshold,
+		Only:            only,
+		MaxFailures:     max(0, *maxFailures),
+		Timeout:         *timeout,
+	}
+	if *failFast {
+		opts.MaxFailures = 1
+	}
+	if only != nil {
+		// Only the failed tests are rerun; the load block is not part of that.
+		opts.RunLoad = false
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			utils.SetSeed(*seed)
+		}
+	})
+
+	switch mode := strings.ToLower(strings.TrimSpace(*dryRunFormat)); mode {
+	case "":
+	case "curl", "http":
+		return dryRun(files, opts, mode)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown dry-run format %q (use curl or http)\n", *dryRunFormat)
+		return 1
+	}
+	if *watchFlag {
+		return watch(ctx, files, opts)
+	}
+
+	var sink events.Sink = events.Nop{}
+	ndjson := false
+	switch strings.ToLower(strings.TrimSpace(*format)) {
+	case "", "text":
+	case "ndjson":
+		ndjson = true
+		sink = events.NewNDJSON(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q (use text or ndjson)\n", *format)
+		return 1
 	}
 
-	reportData, err := runFiles(files, opts)
+	sink.Emit(events.Event{Type: events.RunStart, Files: files})
+	reportData, err := runFiles(ctx, files, opts, sink)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, utils.Red("Error: "+err.Error()))
+		sink.Emit(events.Event{Type: events.RunEnd, Error: err.Error()})
+		fmt.Fprintln(os.Stderr, utils.Red("Error: "+secrets.Redact(err.Error())))
 		return 1
 	}
+	if randomSeed, used := utils.RandomSeed(); used {
+		reportData.Seed = randomSeed
+	}
+	sink.Emit(events.Event{
+		Type:       events.RunEnd,
+		Total:      reportData.Total,
+		Passed:     reportData.Passed,
+		Failed:     reportData.Failed,
+		Skipped:    reportData.Skipped,
+		Cancelled:  reportData.Cancelled,
+		Flaky:      reportData.Flaky,
+		DurationMS: reportData.DurationMS,
+	})
 
-	if opts.GitHubActions {
+	// Stdout carries only events in ndjson mode.
+	if opts.GitHubActions && !ndjson {
 		for _, failure := range reportData.Failures {
-			fmt.Println(gha.FailureAnnotation(failure.File, failure.Test, failure.Why))
+			source := failure.Source
+			if source == "" {
+				source = config.SourceFile(failure.File)
+			}
+			fmt.Println(gha.FailureAnnotation(source, failure.Line, failure.Test, failure.Why))
 		}
 	}
 
@@ -122,19 +258,60 @@ func runCommand(args []string) int {
 			fmt.Fprintf(os.Stderr, "failed to write HTML report: %v\n", err)
 		}
 	}
+	if opts.ReportMDPath != "" {
+		path := config.ResolveOutputPath(files[0], opts.ReportMDPath)
+		if err := report.WriteMarkdown(path, reportData); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write Markdown report: %v\n", err)
+		}
+	}
+	if opts.GitHubActions {
+		if err := gha.WriteStepSummary(report.Markdown(reportData)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write GitHub step summary: %v\n", err)
+		}
+	}
 
-	printRunSummary(reportData)
-	if reportData.Failed > 0 || len(reportData.Flaky) > 0 {
+	var flakyScores map[string]float64
+	if opts.HistoryPath != "" {
+		if err := history.Append(opts.HistoryPath, reportData); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write run history: %v\n", err)
+		} else if entries, err := history.Load(opts.HistoryPath); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read run history: %v\n", err)
+		} else {
+			flakyScores = history.Scores(entries, history.DefaultWindow, opts.FlakyThreshold)
+		}
+	}
+
+	if !ndjson {
+		printRunSummary(reportData, flakyScores)
+	}
+	if interrupted(ctx) {
+		return exitInterrupted
+	}
+	// A run cut short by --timeout or a deadline fails even when every test
+	// it got to passed.
+	if reportData.Failed > 0 || len(reportData.Flaky) > 0 || reportData.Stopped != "" {
 		return 1
 	}
 	return 0
 }
 
-func runFiles(files []string, opts model.RunOptions) (model.RunReport, error) {
+func runFiles(parent context.Context, files []string, opts model.RunOptions, sink events.Sink) (model.RunReport, error) {
 	started := time.Now()
 	snapshots := snapshot.NewManager(".reqres_snapshots")
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		parent, cancel = context.WithTimeoutCause(parent, opts.Timeout, &runner.StopError{
+			Why:     fmt.Sprintf("run exceeded --timeout %s", opts.Timeout),
+			Status:  model.StatusFail,
+			Verb:    "timed out",
+			Pending: model.StatusSkip,
+		})
+		defer cancel()
+	}
+	ctx, limit := runner.NewFailureLimit(parent, opts.MaxFailures)
+	defer limit.Stop()
 
-	fileReports, loadResults, err := runRound(files, opts, snapshots, true)
+	fileReports, loadResults, err := runRound(ctx, files, opts, snapshots, limit, true, sink)
 	if err != nil {
 		return model.RunReport{}, err
 	}
@@ -143,8 +320,9 @@ func runFiles(files []string, opts model.RunOptions) (model.RunReport, error) {
 	if opts.DetectFlakyRuns > 1 {
 		history := map[string]map[model.TestStatus]int{}
 		recordHistory(history, fileReports)
-		for round := 2; round <= opts.DetectFlakyRuns; round++ {
-			roundReports, _, err := runRound(files, opts, snapshots, false)
+		for round := 2; round <= opts.DetectFlakyRuns && ctx.Err() == nil; round++ {
+			// Flaky reruns are silent; run_end reports the final verdict.
+			roundReports, _, err := runRound(ctx, files, opts, snapshots, nil, false, events.Nop{})
 			if err != nil {
 				return model.RunReport{}, err
 			}
@@ -180,22 +358,13 @@ func runFiles(files []string, opts model.RunOptions) (model.RunReport, error) {
 		Files:       fileReports,
 		Flaky:       flakyNames,
 	}
+	out = report.Tally(out)
+	if ctx.Err() != nil {
+		out.Stopped = context.Cause(ctx).Error()
+	}
 	for _, file := range out.Files {
-		out.Total += file.Total
-		for _, test := range file.Tests {
-			switch test.Status {
-			case model.StatusPass:
-				out.Passed++
-			case model.StatusFail, model.StatusFlaky:
-				out.Failed++
-				out.Failures = append(out.Failures, model.FailureEntry{
-					File: file.File,
-					Test: test.Name,
-					Why:  test.Message,
-				})
-			case model.StatusSkip:
-				out.Skipped++
-			}
+		if out.Stopped == "" && file.Stopped != "" {
+			out.Stopped = file.File + ": " + file.Stopped
 		}
 	}
 	out.DurationMS = out.FinishedAt.Sub(out.StartedAt).Milliseconds()
@@ -206,12 +375,115 @@ func runFiles(files []string, opts model.RunOptions) (model.RunReport, error) {
 	return out, nil
 }
 
-func runRound(files []string, opts model.RunOptions, snapshots *snapshot.Manager, includeLoad bool) ([]model.FileReport, []*model.LoadSummary, error) {
+// onlyTests keeps the named tests of cfg together with their `after`
+// chains and the tests waiting on them.
+func onlyTests(cfg model.Config, names []string) model.Config {
+	selected := map[string]bool{}
+	for _, name := range names {
+		selected[name] = true
+	}
+	for _, test := range cfg.Tests {
+		delete(selected, test.Name)
+	}
+	for name := range selected {
+		fmt.Fprintf(os.Stderr, "%s test %q is no longer in %s\n", utils.Yellow("warning:"), name, cfg.File)
+	}
+	for _, name := range names {
+		selected[name] = true
+	}
+	related := runner.Related(cfg.Tests, selected)
+	tests := cfg.Tests
+	cfg.Tests = nil
+	for _, test := range tests {
+		if related[test.Name] {
+			cfg.Tests = append(cfg.Tests, test)
+		}
+	}
+	return cfg
+}
+
+// inFile names the suite an error came from, unless the error already
+// starts with a file position. Load errors always name their file.
+func inFile(file string, err error) error {
+	if config.Located(err) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", file, err)
+}
+
+// checkSuite validates cfg before it runs. Unknown keys are errors with
+// --strict and otherwise printed as warnings when warn is set.
+func checkSuite(cfg model.Config, opts model.RunOptions, warn bool) error {
+	errs := config.Validate(cfg)
+	if opts.Strict {
+		for _, unknown := range cfg.UnknownKeys {
+			errs = append(errs, errors.New(unknown))
+		}
+	} else if warn {
+		for _, unknown := range cfg.UnknownKeys {
+			fmt.Fprintf(os.Stderr, "%s %s\n", utils.Yellow("warning:"), secrets.Redact(unknown))
+		}
+	}
+	if len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, e := range errs {
+			messages = append(messages, e.Error())
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// dryRun prints the requests each suite would send, in execution order,
+// as curl commands or raw HTTP blocks. Nothing is sent.
+func dryRun(files []string, opts model.RunOptions, format string) int {
+	exitCode := 0
+	for _, file := range files {
+		configs, err := config.LoadAllFromFile(file, loadOptions(opts))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, utils.Red("Error: "+secrets.Redact(err.Error())))
+			return 1
+		}
+		for _, cfg := range configs {
+			if err := checkSuite(cfg, opts, true); err != nil {
+				fmt.Fprintln(os.Stderr, utils.Red("Error: "+secrets.Redact(inFile(cfg.File, err).Error())))
+				return 1
+			}
+			comment := "#"
+			if format == "http" {
+				comment = "###"
+			}
+			fmt.Printf("%s %s\n", comment, cfg.File)
+			for _, plan := range runner.DryRun(cfg, opts.Tags) {
+				label := fmt.Sprintf("[wave %d] %s", plan.Wave+1, plan.Test.Name)
+				switch {
+				case plan.Skip != "":
+					fmt.Printf("\n%s [skip] %s: %s\n", comment, plan.Test.Name, plan.Skip)
+					continue
+				case plan.Err != nil:
+					fmt.Printf("\n%s [error] %s: %s\n", comment, plan.Test.Name, secrets.Redact(plan.Err.Error()))
+					exitCode = 1
+					continue
+				case plan.Test.After != "":
+					label += fmt.Sprintf(" (after %s)", plan.Test.After)
+				}
+				rendered := plan.Request.Curl()
+				if format == "http" {
+					rendered = plan.Request.HTTP()
+				}
+				fmt.Printf("\n%s %s\n%s\n", comment, label, strings.TrimSuffix(secrets.Redact(rendered), "\n"))
+			}
+			fmt.Println()
+		}
+	}
+	return exitCode
+}
+
+func runRound(ctx context.Context, files []string, opts model.RunOptions, snapshots *snapshot.Manager, limit *runner.FailureLimit, includeLoad bool, sink events.Sink) ([]model.FileReport, []*model.LoadSummary, error) {
 	type roundResult struct {
-		file   string
-		report model.FileReport
-		load   *model.LoadSummary
-		err    error
+		reports []model.FileReport
+		loads   []*model.LoadSummary
+		err     error
 	}
 	results := make([]roundResult, len(files))
 	sem := make(chan struct{}, max(1, opts.Parallel))
@@ -224,67 +496,31 @@ func runRound(files []string, opts model.RunOptions, snapshots *snapshot.Manager
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			cfg, err := config.LoadFromFile(file, opts.Env)
+			configs, err := config.LoadAllFromFile(file, loadOptions(opts))
 			if err != nil {
-				results[i] = roundResult{file: file, err: err}
+				results[i] = roundResult{err: err}
 				return
 			}
-			if errs := config.Validate(cfg); len(errs) > 0 {
-				messages := make([]string, 0, len(errs))
-				for _, e := range errs {
-					messages = append(messages, e.Error())
-				}
-				results[i] = roundResult{file: file, err: errors.New(strings.Join(messages, "; "))}
-				return
-			}
-
-			fileReport, _ := runner.RunFile(runner.FileRunOptions{
-				FilePath:        file,
-				Config:          cfg,
-				RunOptions:      opts,
-				SnapshotManager: snapshots,
-			})
-
-			var loadSummary *model.LoadSummary
-			if includeLoad && opts.RunLoad && cfg.Load != nil && allowByTags(cfg.Load.Tags, opts.Tags) {
-				expandedLoad, err := expandLoadConfig(*cfg.Load, cfg.Vars)
-				if err != nil {
-					results[i] = roundResult{file: file, err: err}
-					return
-				}
-				expandedLoad.Path, err = utils.ExpandString(expandedLoad.Path, cfg.Vars)
-				if err != nil {
-					results[i] = roundResult{file: file, err: err}
-					return
-				}
-				headers := mergeHeaders(cfg.Defaults.Headers, expandedLoad.Headers)
-				expandedHeaders := map[string]string{}
-				for key, value := range headers {
-					expanded, err := utils.ExpandString(value, cfg.Vars)
-					if err != nil {
-						results[i] = roundResult{file: file, err: err}
-						return
+			result := roundResult{}
+			for _, cfg := range configs {
+				if opts.Only != nil {
+					names, ok := opts.Only[config.CleanLabel(cfg.File)]
+					if !ok {
+						continue
 					}
-					expandedHeaders[key] = expanded
+					cfg = onlyTests(cfg, names)
 				}
-				loadSummary, err = loadtest.Run(expandedLoad, loadtest.Options{
-					BaseURL:   cfg.Base,
-					Headers:   expandedHeaders,
-					Auth:      cfg.Defaults.Auth,
-					TimeoutMS: cfg.Timeout,
-					Retries:   cfg.Retries,
-				})
+				fileReport, loadSummary, err := runSuite(ctx, cfg, opts, snapshots, limit, includeLoad, sink)
 				if err != nil {
-					results[i] = roundResult{file: file, err: err}
+					results[i] = roundResult{err: inFile(cfg.File, err)}
 					return
 				}
+				result.reports = append(result.reports, fileReport)
+				if loadSummary != nil {
+					result.loads = append(result.loads, loadSummary)
+				}
 			}
-
-			results[i] = roundResult{
-				file:   file,
-				report: fileReport,
-				load:   loadSummary,
-			}
+			results[i] = result
 		}(i, file)
 	}
 	wg.Wait()
@@ -293,23 +529,112 @@ func runRound(files []string, opts model.RunOptions, snapshots *snapshot.Manager
 	loads := []*model.LoadSummary{}
 	for _, item := range results {
 		if item.err != nil {
-			return nil, nil, fmt.Errorf("%s: %w", item.file, item.err)
-		}
-		fileReports = append(fileReports, item.report)
-		if item.load != nil {
-			loads = append(loads, item.load)
+			return nil, nil, item.err
 		}
+		fileReports = append(fileReports, item.reports...)
+		loads = append(loads, item.loads...)
 	}
 	return fileReports, loads, nil
 }
 
+// runSuite validates and runs one suite document, plus its load phase.
+func runSuite(ctx context.Context, cfg model.Config, opts model.RunOptions, snapshots *snapshot.Manager, limit *runner.FailureLimit, includeLoad bool, sink events.Sink) (model.FileReport, *model.LoadSummary, error) {
+	if err := checkSuite(cfg, opts, includeLoad); err != nil {
+		return model.FileReport{}, nil, err
+	}
+	run := ctx
+	if cfg.Deadline != "" {
+		raw, err := utils.ExpandString(cfg.Deadline, cfg.Vars)
+		if err != nil {
+			return model.FileReport{}, nil, fmt.Errorf("deadline: %w", err)
+		}
+		deadline, err := time.ParseDuration(raw)
+		if err != nil {
+			return model.FileReport{}, nil, fmt.Errorf("deadline: invalid duration %q", raw)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, deadline, &runner.StopError{
+			Why:     fmt.Sprintf("suite exceeded its deadline of %s", raw),
+			Status:  model.StatusFail,
+			Verb:    "timed out",
+			Pending: model.StatusSkip,
+		})
+		defer cancel()
+	}
+
+	fileReport, _ := runner.RunFile(runner.FileRunOptions{
+		FilePath:        cfg.File,
+		Config:          cfg,
+		RunOptions:      opts,
+		SnapshotManager: snapshots,
+		Events:          sink,
+		Context:         ctx,
+		Failures:        limit,
+	})
+	// Only the suite's own deadline is recorded here; the run records why
+	// the whole run stopped.
+	suiteStopped := func() {
+		if ctx.Err() != nil && run.Err() == nil {
+			fileReport.Stopped = context.Cause(ctx).Error()
+		}
+	}
+
+	if !includeLoad || !opts.RunLoad || ctx.Err() != nil || cfg.Load == nil || !allowByTags(cfg.Load.Tags, opts.Tags) {
+		suiteStopped()
+		return fileReport, nil, nil
+	}
+	loadVars := utils.Seeded(cfg.Vars, cfg.File+"::load")
+	expandedLoad, err := expandLoadConfig(*cfg.Load, loadVars)
+	if err != nil {
+		return model.FileReport{}, nil, err
+	}
+	expandedLoad.Path, err = utils.ExpandString(expandedLoad.Path, loadVars)
+	if err != nil {
+		return model.FileReport{}, nil, err
+	}
+	headers := mergeHeaders(cfg.Defaults.Headers, expandedLoad.Headers)
+	headerNames := make([]string, 0, len(headers))
+	for key := range headers {
+		headerNames = append(headerNames, key)
+	}
+	sort.Strings(headerNames)
+	expandedHeaders := map[string]string{}
+	for _, key := range headerNames {
+		value := headers[key]
+		expanded, err := utils.ExpandString(value, loadVars)
+		if err != nil {
+			return model.FileReport{}, nil, err
+		}
+		expandedHeaders[key] = expanded
+	}
+	loadSummary, err := loadtest.Run(ctx, expandedLoad, loadtest.Options{
+		BaseURL:   cfg.Base,
+		Headers:   expandedHeaders,
+		Auth:      cfg.Defaults.Auth,
+		TimeoutMS: cfg.Timeout,
+		Retries:   cfg.Retries,
+		File:      cfg.File,
+		Events:    sink,
+	})
+	if err != nil {
+		return model.FileReport{}, nil, err
+	}
+	suiteStopped()
+	return fileReport, loadSummary, nil
+}
+
 func validateCommand(args []string) int {
 	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	env := fs.String("env", "", "environment override name")
-	if err := fs.Parse(reorderArgs(args, map[string]bool{"--env": true})); err != nil {
+	envFile := fs.String("env-file", "", "read variables from this file instead of the .env next to the suite")
+	cliVars := varFlags{}
+	fs.Var(cliVars, "var", "set a variable as key=value (repeatable)")
+	strict := fs.Bool("strict", false, "treat unknown keys as errors")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"--env": true, "--env-file": true, "--var": true, "--strict": false})); err != nil {
 		return 1
 	}
+	loadOpts := config.LoadOptions{Env: strings.TrimSpace(*env), EnvFile: strings.TrimSpace(*envFile), Vars: cliVars}
 	files := fs.Args()
 	if len(files) == 0 {
 		fmt.Fprintln(os.Stderr, "validate requires at least one yaml file")
@@ -318,22 +643,35 @@ func validateCommand(args []string) int {
 
 	hasErrors := false
 	for _, file := range files {
-		cfg, err := config.LoadFromFile(file, strings.TrimSpace(*env))
+		configs, err := config.LoadAllFromFile(file, loadOpts)
 		if err != nil {
 			hasErrors = true
 			fmt.Printf("%s %s\n", utils.Red("INVALID"), file)
-			fmt.Printf("  %v\n", err)
-			continue
-		}
-		errs := config.Validate(cfg)
-		if len(errs) == 0 {
-			fmt.Printf("%s %s\n", utils.Green("VALID"), file)
+			fmt.Printf("  %s\n", secrets.Redact(err.Error()))
 			continue
 		}
-		hasErrors = true
-		fmt.Printf("%s %s\n", utils.Red("INVALID"), file)
-		for _, err := range errs {
-			fmt.Printf("  - %v\n", err)
+		for _, cfg := range configs {
+			errs := config.Validate(cfg)
+			warnings := cfg.Warnings
+			if *strict {
+				for _, unknown := range cfg.UnknownKeys {
+					errs = append(errs, errors.New(unknown))
+				}
+			} else {
+				warnings = append(warnings, cfg.UnknownKeys...)
+			}
+			if len(errs) == 0 {
+				fmt.Printf("%s %s\n", utils.Green("VALID"), cfg.File)
+			} else {
+				hasErrors = true
+				fmt.Printf("%s %s\n", utils.Red("INVALID"), cfg.File)
+				for _, err := range errs {
+					fmt.Printf("  - %s\n", secrets.Redact(err.Error()))
+				}
+			}
+			for _, warning := range warnings {
+				fmt.Printf("  %s %s\n", utils.Yellow("warning:"), secrets.Redact(warning))
+			}
 		}
 	}
 	if hasErrors {
@@ -346,8 +684,11 @@ func mockCommand(args []string) int {
 	fs := flag.NewFlagSet("mock", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	env := fs.String("env", "", "environment override name")
+	envFile := fs.String("env-file", "", "read variables from this file instead of the .env next to the suite")
+	cliVars := varFlags{}
+	fs.Var(cliVars, "var", "set a variable as key=value (repeatable)")
 	port := fs.Int("port", 8080, "port to listen on")
-	if err := fs.Parse(reorderArgs(args, map[string]bool{"--env": true, "--port": true})); err != nil {
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"--env": true, "--env-file": true, "--var": true, "--port": true})); err != nil {
 		return 1
 	}
 	files := fs.Args()
@@ -356,7 +697,7 @@ func mockCommand(args []string) int {
 		return 1
 	}
 
-	cfg, err := config.LoadFromFile(files[0], strings.TrimSpace(*env))
+	cfg, err := config.LoadFromFile(files[0], config.LoadOptions{Env: strings.TrimSpace(*env), EnvFile: strings.TrimSpace(*envFile), Vars: cliVars})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
@@ -389,6 +730,148 @@ func generateCommand(args []string) int {
 	return 0
 }
 
+func fmtCommand(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	check := fs.Bool("check", false, "list files that are not formatted and exit 1 instead of rewriting them")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"--check": false})); err != nil {
+		return 1
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "fmt requires at least one yaml file")
+		return 1
+	}
+
+	exitCode := 0
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+			continue
+		}
+		formatted, err := config.Format(content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		if bytes.Equal(content, formatted) {
+			continue
+		}
+		if *check {
+			fmt.Println(file)
+			exitCode = 1
+			continue
+		}
+		if err := os.WriteFile(file, formatted, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+			continue
+		}
+		fmt.Printf("Formatted %s\n", file)
+	}
+	return exitCode
+}
+
+func listCommand(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	tagsRaw := fs.String("tags", "", "comma-separated tags to include")
+	env := fs.String("env", "", "environment override name")
+	envFile := fs.String("env-file", "", "read variables from this file instead of the .env next to the suite")
+	cliVars := varFlags{}
+	fs.Var(cliVars, "var", "set a variable as key=value (repeatable)")
+	graphFormat := fs.String("graph", "", "print the dependency graph as dot or mermaid instead of the test list")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"--tags": true, "--env": true, "--env-file": true, "--var": true, "--graph": true})); err != nil {
+		return 1
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "list requires at least one yaml file")
+		return 1
+	}
+	render := map[string]func([]report.GraphFile) string{"": nil, "dot": report.Dot, "mermaid": report.Mermaid}
+	renderGraph, ok := render[strings.ToLower(strings.TrimSpace(*graphFormat))]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown graph format %q (use dot or mermaid)\n", *graphFormat)
+		return 1
+	}
+
+	loadOpts := config.LoadOptions{Env: strings.TrimSpace(*env), EnvFile: strings.TrimSpace(*envFile), Vars: cliVars}
+	tags := parseCSV(*tagsRaw)
+	graphs := []report.GraphFile{}
+	vars := map[string]map[string]any{}
+	for _, file := range files {
+		configs, err := config.LoadAllFromFile(file, loadOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, secrets.Redact(err.Error()))
+			return 1
+		}
+		for _, cfg := range configs {
+			graphs = append(graphs, report.GraphFile{File: cfg.File, Graph: runner.BuildGraph(cfg.Tests, tags)})
+			vars[cfg.File] = cfg.Vars
+		}
+	}
+	if renderGraph != nil {
+		fmt.Print(secrets.Redact(renderGraph(graphs)))
+		return 0
+	}
+
+	for _, file := range graphs {
+		waves := len(file.Graph.Waves())
+		fmt.Printf("%s (%d tests, %d waves)\n", utils.Blue(file.File), len(file.Graph.Nodes), waves)
+		for _, node := range file.Graph.Nodes {
+			test := node.Test
+			wave := fmt.Sprintf("wave %d", node.Wave+1)
+			if node.Wave < 0 {
+				wave = utils.Red("cycle")
+			}
+			line := fmt.Sprintf("  [%s] %s (%s %s)", wave, test.Name, test.Method, utils.ExpandKnown(test.Path, vars[file.File]))
+			if len(test.Tags) > 0 {
+				line += " tags: " + strings.Join(test.Tags, ", ")
+			}
+			if chain := file.Graph.Chain(test.Name); len(chain) > 0 {
+				if node.Unselected {
+					chain[0] += " (not selected)"
+				}
+				line += " after: " + strings.Join(chain, " <- ")
+			}
+			fmt.Println(secrets.Redact(line))
+		}
+	}
+	return 0
+}
+
+func schemaCommand(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	output := fs.String("o", "", "write the schema to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config.JSONSchema()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data := buf.Bytes()
+	if path := strings.TrimSpace(*output); path != "" {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("Generated %s\n", path)
+		return 0
+	}
+	os.Stdout.Write(data)
+	return 0
+}
+
 func ghaInitCommand(args []string) int {
 	target := ""
 	if len(args) > 0 {
@@ -405,19 +888,180 @@ func ghaInitCommand(args []string) int {
 	return 0
 }
 
+func reportCommand(args []string) int {
+	if len(args) > 0 && args[0] == "merge" {
+		return reportMergeCommand(args[1:])
+	}
+	if len(args) == 0 || args[0] != "diff" {
+		fmt.Fprintln(os.Stderr, "usage: reqres report diff <baseline.json> <current.json>")
+		fmt.Fprintln(os.Stderr, "       reqres report merge <original.json> <rerun.json> [-o merged.json]")
+		return 1
+	}
+	fs := flag.NewFlagSet("report diff", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	thresholdRaw := fs.String("threshold", "20%", "latency change to report (e.g. 20% or 150ms)")
+	format := fs.String("format", "table", "output format: table or markdown")
+	failOnRegression := fs.Bool("fail-on-regression", false, "exit 1 when regressions are found")
+	if err := fs.Parse(reorderArgs(args[1:], map[string]bool{
+		"--threshold":          true,
+		"--format":             true,
+		"--fail-on-regression": false,
+	})); err != nil {
+		return 1
+	}
+	rest := fs.Args()
+	if len(rest) != 2 {
+		fmt.Fprintln(os.Stderr, "report diff requires a baseline and a current report")
+		return 1
+	}
+	threshold, err := report.ParseThreshold(*thresholdRaw)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	baseline, err := report.ReadJSON(rest[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	current, err := report.ReadJSON(rest[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	diff := report.Diff(baseline, current, threshold)
+	switch strings.ToLower(strings.TrimSpace(*format)) {
+	case "markdown", "md":
+		fmt.Print(diff.Markdown())
+	case "table", "":
+		fmt.Print(diff.Text())
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q (use table or markdown)\n", *format)
+		return 1
+	}
+	if *failOnRegression && diff.HasRegressions() {
+		return 1
+	}
+	return 0
+}
+
+func reportMergeCommand(args []string) int {
+	fs := flag.NewFlagSet("report merge", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	output := fs.String("o", "", "write the merged report to this file instead of stdout")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"-o": true})); err != nil {
+		return 1
+	}
+	rest := fs.Args()
+	if len(rest) != 2 {
+		fmt.Fprintln(os.Stderr, "report merge requires the original report and the rerun report")
+		return 1
+	}
+	original, err := report.ReadJSON(rest[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rerun, err := report.ReadJSON(rest[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	merged := report.Merge(original, rerun)
+	if path := strings.TrimSpace(*output); path != "" {
+		if err := report.WriteJSON(path, merged); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("Merged %s into %s: total=%d pass=%d fail=%d skip=%d\n", rest[1], path, merged.Total, merged.Passed, merged.Failed, merged.Skipped)
+	} else {
+		content, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(string(content))
+	}
+	if merged.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func historyCommand(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	path := fs.String("file", history.DefaultPath, "run history file")
+	last := fs.Int("last", history.DefaultWindow, "number of most recent runs to analyze")
+	threshold := fs.Float64("threshold", history.DefaultThreshold, "flakiness score that labels a test flaky")
+	onlyFlaky := fs.Bool("only-flaky", false, "list only tests above the threshold")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{
+		"--file":       true,
+		"--last":       true,
+		"--threshold":  true,
+		"--only-flaky": false,
+	})); err != nil {
+		return 1
+	}
+
+	entries, err := history.Load(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No run history in %s (record runs with `reqres run --history`)\n", *path)
+		return 0
+	}
+	window := max(1, *last)
+	stats := history.Analyze(entries, window)
+	fmt.Printf("Last %d of %d runs from %s\n\n", min(window, len(entries)), len(entries), *path)
+	fmt.Printf("%-48s %5s %7s %6s %9s  %s\n", "TEST", "RUNS", "PASS%", "FLAKY", "AVG(ms)", "TREND")
+	for _, item := range stats {
+		flaky := item.Runs > 1 && item.Flakiness >= *threshold
+		if *onlyFlaky && !flaky {
+			continue
+		}
+		score := fmt.Sprintf("%6.2f", item.Flakiness)
+		if flaky {
+			score = utils.Yellow(score)
+		}
+		fmt.Printf("%-48s %5d %6.0f%% %s %9.1f  %s\n",
+			truncate(item.Key(), 48), item.Runs, item.PassRate*100, score, item.AvgMS, history.Trend(item.Durations))
+	}
+	return 0
+}
+
+func truncate(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
 func printUsage() {
-	fmt.Println(`ReqRes - API testing CLI
+	fmt.Print(`ReqRes - API testing CLI
 
 Usage:
-  reqres run <file...> [--tags smoke] [--env staging] [--parallel 8]
-  reqres validate <file...>
+  reqres run <file...> [--tags smoke] [--env staging] [--parallel 8] [--format ndjson] [--seed 42] [--var key=value] [--strict] [--dry-run [curl|http]] [--watch] [--fail-fast | --max-failures N] [--timeout 10m]
+  reqres run [file...] --rerun-failed reports/result.json
+  reqres validate <file...> [--strict]
+  reqres fmt <file...> [--check]
+  reqres list <file...> [--tags smoke] [--env staging] [--graph dot|mermaid]
   reqres mock <file> [--port 8080]
   reqres generate <openapi.json|yaml> [-o tests.yaml]
   reqres gha-init [path]
+  reqres schema [-o reqres.schema.json]
+  reqres report diff <baseline.json> <current.json> [--threshold 20%] [--format markdown]
+  reqres report merge <original.json> <rerun.json> [-o merged.json]
+  reqres history [--file .reqres_history.jsonl] [--last 20] [--only-flaky]
 `)
 }
 
-func printRunSummary(data model.RunReport) {
+func printRunSummary(data model.RunReport, flakyScores map[string]float64) {
+	data = secrets.RedactReport(data)
 	for _, file := range data.Files {
 		fmt.Printf("\n%s (%d ms)\n", utils.Blue(file.File), file.Duration)
 		for _, test := range file.Tests {
@@ -431,8 +1075,13 @@ func printRunSummary(data model.RunReport) {
 				label = utils.Yellow("SKIP")
 			case model.StatusFlaky:
 				label = utils.Yellow("FLAKY")
+			case model.StatusCancelled:
+				label = utils.Yellow("CANCELLED")
 			}
 			fmt.Printf("  [%s] %s (%s %s)", label, test.Name, test.Method, test.Path)
+			if score, ok := flakyScores[flakyKey(file.File, test.Name)]; ok {
+				fmt.Printf(" %s", utils.Yellow(fmt.Sprintf("[flaky %.2f]", score)))
+			}
 			if test.Message != "" && test.Message != "ok" {
 				fmt.Printf(" - %s", test.Message)
 			}
@@ -440,14 +1089,30 @@ func printRunSummary(data model.RunReport) {
 		}
 	}
 
-	fmt.Printf("\nSummary: total=%d pass=%d fail=%d skip=%d duration=%dms\n",
-		data.Total, data.Passed, data.Failed, data.Skipped, data.DurationMS)
+	fmt.Printf("\nSummary: total=%d pass=%d fail=%d skip=%d", data.Total, data.Passed, data.Failed, data.Skipped)
+	if data.Cancelled > 0 {
+		fmt.Printf(" cancelled=%d", data.Cancelled)
+	}
+	fmt.Printf(" duration=%dms\n", data.DurationMS)
+	if data.Stopped != "" {
+		fmt.Println(utils.Yellow("Stopped early: " + data.Stopped))
+	}
 	if len(data.Flaky) > 0 {
 		fmt.Printf("Flaky tests: %s\n", strings.Join(data.Flaky, ", "))
 	}
+	if data.Failed > 0 && data.Seed != 0 {
+		fmt.Printf("Random seed: %d (reproduce with --seed %d)\n", data.Seed, data.Seed)
+	}
+	if len(flakyScores) > 0 {
+		fmt.Println(utils.Yellow(fmt.Sprintf("History: %d test(s) above flakiness threshold (see `reqres history`)", len(flakyScores))))
+	}
 	if data.Load != nil {
-		fmt.Printf("Load: %s %s users=%d requests=%d success=%d fail=%d avg=%0.2fms p95=%0.2fms\n",
-			data.Load.Method, data.Load.Path, data.Load.Users, data.Load.Requests, data.Load.Successes, data.Load.Failures, data.Load.AvgMS, data.Load.P95MS)
+		stopped := ""
+		if data.Load.Cancelled {
+			stopped = " (stopped early)"
+		}
+		fmt.Printf("Load: %s %s users=%d requests=%d success=%d fail=%d avg=%0.2fms p95=%0.2fms%s\n",
+			data.Load.Method, data.Load.Path, data.Load.Users, data.Load.Requests, data.Load.Successes, data.Load.Failures, data.Load.AvgMS, data.Load.P95MS, stopped)
 	}
 }
 
@@ -559,7 +1224,7 @@ func fillDefaultForBareFlag(args []string, flagName string, defaultValue string)
 		if item != flagName {
 			continue
 		}
-		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && !isSuiteFile(args[i+1]) {
 			continue
 		}
 		out = append(out, defaultValue)
@@ -567,6 +1232,64 @@ func fillDefaultForBareFlag(args []string, flagName string, defaultValue string)
 	return out
 }
 
+func isSuiteFile(arg string) bool {
+	ext := strings.ToLower(filepath.Ext(arg))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// failedSuiteFiles lists the suite files behind the failures of a report.
+func failedSuiteFiles(data model.RunReport) []string {
+	var files []string
+	seen := map[string]bool{}
+	for _, failure := range data.Failures {
+		file := config.SourceFile(failure.File)
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// failuresIn reports whether any suite in only, as built by
+// report.FailedTests, comes from one of files.
+func failuresIn(only map[string][]string, files []string) bool {
+	for label := range only {
+		for _, file := range files {
+			if config.SourceFile(label) == config.CleanLabel(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func loadOptions(opts model.RunOptions) config.LoadOptions {
+	return config.LoadOptions{Env: opts.Env, EnvFile: opts.EnvFile, Vars: opts.Vars}
+}
+
+// varFlags collects repeated --var key=value flags.
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	parts := make([]string, 0, len(v))
+	for key, value := range v {
+		parts = append(parts, key+"="+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (v varFlags) Set(raw string) error {
+	key, value, ok := strings.Cut(raw, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", raw)
+	}
+	v[key] = value
+	return nil
+}
+
 func reorderArgs(args []string, takesValue map[string]bool) []string {
 	flags := make([]string, 0, len(args))
 	positional := make([]string, 0, len(args))
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/runner"
+	"github.com/DevrajJain04/reqres/internal/utils"
+)
+
+// exitInterrupted is the exit code of a run stopped by SIGINT or SIGTERM,
+// following the shell convention of 128 + SIGINT.
+const exitInterrupted = 130
+
+// interruptGrace is how long requests in flight may take to finish after
+// an interrupt.
+const interruptGrace = 2 * time.Second
+
+var interruptStop = &runner.StopError{
+	Why:     "run interrupted",
+	Status:  model.StatusCancelled,
+	Verb:    "cancelled",
+	Pending: model.StatusCancelled,
+	Grace:   interruptGrace,
+}
+
+// interruptContext is cancelled on the first SIGINT or SIGTERM: no new test
+// starts, requests in flight get interruptGrace, and the rest is reported
+// as cancelled. A second signal exits right away.
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(os.Stderr, "\n%s received %s, stopping (again to quit now)\n", utils.Yellow("warning:"), sig)
+			cancel(interruptStop)
+		case <-done:
+			return
+		}
+		select {
+		case <-signals:
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(context.Canceled)
+	}
+}
+
+// interrupted reports whether ctx was cancelled by interruptContext.
+func interrupted(ctx context.Context) bool {
+	return context.Cause(ctx) == interruptStop
+}
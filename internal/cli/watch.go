@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/config"
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/runner"
+	"github.com/DevrajJain04/reqres/internal/secrets"
+	"github.com/DevrajJain04/reqres/internal/snapshot"
+	"github.com/DevrajJain04/reqres/internal/utils"
+)
+
+const watchInterval = 500 * time.Millisecond
+
+// watchedSuite is what watch mode remembers about one suite between runs.
+type watchedSuite struct {
+	// suite fingerprints everything but the tests; when it changes, every
+	// test reruns.
+	suite   string
+	tests   map[string]string
+	order   []string
+	results map[string]model.TestResult
+}
+
+type watcher struct {
+	ctx       context.Context
+	files     []string
+	opts      model.RunOptions
+	snapshots *snapshot.Manager
+	suites    map[string]*watchedSuite
+	// sources maps each suite file to everything it was loaded from.
+	sources map[string][]string
+}
+
+// watch runs the suites, then polls their files and reruns the tests whose
+// definitions changed, with their dependants and the dependencies those
+// need for captures. It runs until ctx is cancelled by Ctrl+C.
+func watch(ctx context.Context, files []string, opts model.RunOptions) int {
+	w := &watcher{
+		ctx:       ctx,
+		files:     files,
+		opts:      opts,
+		snapshots: snapshot.NewManager(".reqres_snapshots"),
+		suites:    map[string]*watchedSuite{},
+		sources:   map[string][]string{},
+	}
+	for {
+		w.round()
+		stamps := w.stamps()
+		for current := stamps; current == stamps; current = w.stamps() {
+			select {
+			case <-ctx.Done():
+				return 0
+			case <-time.After(watchInterval):
+			}
+		}
+		// Editors often write in several steps; let them finish.
+		time.Sleep(watchInterval / 5)
+	}
+}
+
+func (w *watcher) round() {
+	started := time.Now()
+	var problems []string
+	ran := 0
+	changed := map[string]int{}
+	for _, file := range w.files {
+		configs, err := config.LoadAllFromFile(file, loadOptions(w.opts))
+		if err != nil {
+			problems = append(problems, secrets.Redact(err.Error()))
+			// Keep watching what the last good load read, plus whatever
+			// this one reached, so fixing a broken include reruns.
+			w.setSources(file, append(w.sources[file], config.SourceFiles(file, loadOptions(w.opts))...))
+			continue
+		}
+		sources := []string{file}
+		loaded := map[string]bool{}
+		for _, cfg := range configs {
+			loaded[cfg.File] = true
+			sources = append(sources, cfg.Includes...)
+			sources = append(sources, cfg.DataFiles...)
+			if err := checkSuite(cfg, w.opts, false); err != nil {
+				problems = append(problems, secrets.Redact(inFile(cfg.File, err).Error()))
+				continue
+			}
+			count := w.runSuite(cfg)
+			if count > 0 {
+				changed[cfg.File] = count
+			}
+			ran += count
+		}
+		w.setSources(file, sources)
+		// Forget documents that were removed from the file, so their last
+		// results no longer count.
+		for label := range w.suites {
+			if config.SourceFile(label) == file && !loaded[label] {
+				delete(w.suites, label)
+			}
+		}
+	}
+	w.printSummary(started, ran, changed, problems)
+}
+
+func (w *watcher) setSources(file string, sources []string) {
+	slices.Sort(sources)
+	w.sources[file] = slices.Compact(sources)
+}
+
+// runSuite reruns the changed part of cfg and returns how many tests ran.
+func (w *watcher) runSuite(cfg model.Config) int {
+	suiteKey := fingerprint(suiteOnly(cfg))
+	tests := map[string]string{}
+	for _, test := range cfg.Tests {
+		test.Pos = model.Position{}
+		tests[test.Name] = fingerprint(test)
+	}
+	order := []string{}
+	for _, node := range runner.BuildGraph(cfg.Tests, w.opts.Tags).Nodes {
+		order = append(order, node.Test.Name)
+	}
+
+	previous, seen := w.suites[cfg.File]
+	selected := map[string]bool{}
+	for name := range tests {
+		if !seen || previous.suite != suiteKey || previous.tests[name] != tests[name] {
+			selected[name] = true
+		}
+	}
+	selected = runner.Related(cfg.Tests, selected)
+
+	state := &watchedSuite{suite: suiteKey, tests: tests, order: order, results: map[string]model.TestResult{}}
+	if seen {
+		for name, result := range previous.results {
+			if _, ok := tests[name]; ok {
+				state.results[name] = result
+			}
+		}
+	}
+	w.suites[cfg.File] = state
+	if len(selected) == 0 {
+		return 0
+	}
+
+	subset := cfg
+	subset.Tests = nil
+	for _, test := range cfg.Tests {
+		if selected[test.Name] {
+			subset.Tests = append(subset.Tests, test)
+		}
+	}
+	report, _ := runner.RunFile(runner.FileRunOptions{
+		FilePath:        cfg.File,
+		Config:          subset,
+		RunOptions:      w.opts,
+		SnapshotManager: w.snapshots,
+		Context:         w.ctx,
+	})
+	for _, result := range report.Tests {
+		state.results[result.Name] = result
+	}
+	return len(report.Tests)
+}
+
+func suiteOnly(cfg model.Config) model.Config {
+	cfg.Tests = nil
+	cfg.File = ""
+	cfg.Positions = nil
+	cfg.Includes = nil
+	cfg.DataFiles = nil
+	cfg.Warnings = nil
+	cfg.UnknownKeys = nil
+	return cfg
+}
+
+func fingerprint(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+	return string(data)
+}
+
+// stamps summarizes the size and modification time of every watched file.
+// Files that do not exist yet are watched too, so creating a .env counts.
+func (w *watcher) stamps() string {
+	var paths []string
+	for _, file := range w.files {
+		paths = append(paths, file)
+		paths = append(paths, w.sources[file]...)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(&b, "%s missing\n", path)
+		}
+	}
+	return b.String()
+}
+
+func (w *watcher) printSummary(started time.Time, ran int, changed map[string]int, problems []string) {
+	// Clear the screen and move the cursor home.
+	fmt.Print("\033[H\033[2J")
+	total := 0
+	for _, state := range w.suites {
+		total += len(state.order)
+	}
+	fmt.Printf("%s %s ran %d of %d tests in %d ms\n", utils.Blue("reqres watch"), started.Format("15:04:05"), ran, total, time.Since(started).Milliseconds())
+	suites := make([]string, 0, len(changed))
+	for suite, count := range changed {
+		suites = append(suites, fmt.Sprintf("%s (%d)", suite, count))
+	}
+	sort.Strings(suites)
+	if len(suites) > 0 {
+		fmt.Printf("  rerun: %s\n", strings.Join(suites, ", "))
+	}
+
+	passed, failed, skipped := 0, 0, 0
+	var failures []string
+	keys := make([]string, 0, len(w.suites))
+	for key := range w.suites {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		state := w.suites[key]
+		for _, name := range state.order {
+			result, ok := state.results[name]
+			if !ok {
+				continue
+			}
+			switch result.Status {
+			case model.StatusPass:
+				passed++
+			case model.StatusFail:
+				failed++
+				failures = append(failures, fmt.Sprintf("  %s %s > %s - %s", utils.Red("FAIL"), key, name, result.Message))
+			case model.StatusSkip:
+				skipped++
+			}
+		}
+	}
+	fmt.Printf("\n%s  %s  %s\n", utils.Green(fmt.Sprintf("pass=%d", passed)), utils.Red(fmt.Sprintf("fail=%d", failed)), utils.Yellow(fmt.Sprintf("skip=%d", skipped)))
+	for _, failure := range failures {
+		fmt.Println(secrets.Redact(failure))
+	}
+	for _, problem := range problems {
+		fmt.Printf("\n%s %s\n", utils.Red("Error:"), problem)
+	}
+	fmt.Println("\nWatching for changes. Press Ctrl+C to stop.")
+}
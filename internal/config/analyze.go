@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/assertion"
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/utils"
+)
+
+// analyze finds problems that would otherwise only surface mid-run: unknown
+// variables, captures used without an `after` path to the capturing test,
+// invalid JSONPaths, regexes and len expressions, and bad durations.
+func analyze(cfg model.Config, fail func(path string, format string, args ...any)) {
+	defined := map[string]bool{}
+	for name := range cfg.Vars {
+		defined[name] = true
+	}
+	for _, override := range cfg.Envs {
+		for name := range override.Vars {
+			defined[name] = true
+		}
+		for name := range override.Secrets {
+			defined[name] = true
+		}
+	}
+
+	capturedBy := map[string][]string{}
+	testIndex := map[string]int{}
+	for i, test := range cfg.Tests {
+		testIndex[test.Name] = i
+		for _, key := range sortedStringKeys(test.Capture) {
+			capturedBy[key] = append(capturedBy[key], test.Name)
+			if err := assertion.ValidatePath(test.Capture[key]); err != nil {
+				fail(fmt.Sprintf("tests[%d].capture.%s", i, key), "capture %s: %v", key, err)
+			}
+		}
+	}
+
+	// pathError walks a dotted or indexed reference into a static variable.
+	// Captures, and roots only some envs define, are not known before the
+	// run, so only their root is checked.
+	pathError := func(ref string) error {
+		root := utils.RootVar(ref)
+		if root == ref || len(capturedBy[root]) > 0 {
+			return nil
+		}
+		if _, ok := cfg.Vars[root]; !ok {
+			return nil
+		}
+		_, _, err := utils.LookupVar(ref, cfg.Vars)
+		return err
+	}
+
+	reported := map[string]bool{}
+	report := func(path string, format string, args ...any) {
+		key := path + "\x00" + fmt.Sprintf(format, args...)
+		if reported[key] {
+			return
+		}
+		reported[key] = true
+		fail(path, format, args...)
+	}
+
+	for i, test := range cfg.Tests {
+		location := fmt.Sprintf("tests[%d]", i)
+		upstream := upstreamTests(cfg.Tests, testIndex, i)
+		check := func(path string, text string) {
+			refs, err := utils.References(text)
+			if err != nil {
+				report(path, "%v", err)
+			}
+			for _, ref := range refs {
+				name := ref
+				if !defined[name] && len(capturedBy[name]) == 0 {
+					name = utils.RootVar(ref)
+				}
+				if defined[name] {
+					if err := pathError(ref); err != nil {
+						report(path, "test %q uses %q: %v", test.Name, ref, err)
+					}
+					continue
+				}
+				capturers := capturedBy[name]
+				if len(capturers) == 0 {
+					report(path, "test %q uses undefined variable %q", test.Name, ref)
+					continue
+				}
+				if !anyIn(capturers, upstream) {
+					report(path, "test %q uses %q, captured by %s, but does not run after it (add `after: %s`)",
+						test.Name, ref, quoteNames(capturers), capturers[0])
+				}
+			}
+		}
+
+		check(location+".path", test.Path)
+		if strings.TrimSpace(test.Auth) != "" {
+			check(location+".auth", test.Auth)
+		} else {
+			check("defaults.auth", cfg.Defaults.Auth)
+		}
+		for _, name := range sortedStringKeys(cfg.Defaults.Headers) {
+			if _, ok := test.Headers[name]; !ok {
+				check("defaults.headers."+name, cfg.Defaults.Headers[name])
+			}
+		}
+		for _, name := range sortedStringKeys(test.Headers) {
+			check(location+".headers."+name, test.Headers[name])
+		}
+		walkStrings(location+".query", test.Query, check)
+		walkStrings(location+".body", test.Body, check)
+		walkStrings(location+".check", test.Check, check)
+
+		for _, issue := range assertion.Lint(test.Check) {
+			path := location + ".check"
+			if issue.Key != "" {
+				path += "." + issue.Key
+			}
+			fail(path, "%s", issue.Message)
+		}
+		if test.Mock != nil {
+			checkDuration(fail, location+".mock.delay", test.Mock.Delay)
+		}
+	}
+
+	if cfg.Load != nil {
+		// Load requests only see static variables, never captures.
+		check := func(path string, text string) {
+			refs, err := utils.References(text)
+			if err != nil {
+				fail(path, "%v", err)
+			}
+			for _, ref := range refs {
+				if !defined[ref] && !defined[utils.RootVar(ref)] {
+					fail(path, "load uses undefined variable %q", ref)
+				} else if err := pathError(ref); err != nil {
+					fail(path, "load uses %q: %v", ref, err)
+				}
+			}
+		}
+		check("load.path", cfg.Load.Path)
+		for _, name := range sortedStringKeys(cfg.Load.Headers) {
+			check("load.headers."+name, cfg.Load.Headers[name])
+		}
+		walkStrings("load.query", cfg.Load.Query, check)
+		walkStrings("load.body", cfg.Load.Body, check)
+		walkStrings("load.check", cfg.Load.Check, check)
+		for _, issue := range assertion.Lint(cfg.Load.Check) {
+			path := "load.check"
+			if issue.Key != "" {
+				path += "." + issue.Key
+			}
+			fail(path, "%s", issue.Message)
+		}
+		checkDuration(fail, "load.duration", cfg.Load.Duration)
+		checkDuration(fail, "load.ramp_up", cfg.Load.RampUp)
+	}
+
+	checkDuration(fail, "deadline", cfg.Deadline)
+	if cfg.Mock != nil {
+		checkDuration(fail, "mock.delay", cfg.Mock.Delay)
+		for i, route := range cfg.Mock.Routes {
+			checkDuration(fail, fmt.Sprintf("mock.routes[%d].delay", i), route.Delay)
+		}
+	}
+}
+
+// upstreamTests returns the tests that are guaranteed to finish before
+// tests[i] by following its `after` chain.
+func upstreamTests(tests []model.TestCase, index map[string]int, i int) map[string]bool {
+	out := map[string]bool{}
+	for name := tests[i].After; name != "" && !out[name]; {
+		out[name] = true
+		j, ok := index[name]
+		if !ok {
+			break
+		}
+		name = tests[j].After
+	}
+	return out
+}
+
+func checkDuration(fail func(path string, format string, args ...any), path string, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "${") {
+		return
+	}
+	if _, err := time.ParseDuration(value); err != nil {
+		fail(path, "%s: invalid duration %q (use e.g. 500ms, 30s, 2m)", path, value)
+	}
+}
+
+// walkStrings calls fn for every string inside value with its config path.
+func walkStrings(path string, value any, fn func(path string, text string)) {
+	switch t := value.(type) {
+	case string:
+		fn(path, t)
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkStrings(path+"."+key, t[key], fn)
+		}
+	case []any:
+		for i, item := range t {
+			walkStrings(fmt.Sprintf("%s[%d]", path, i), item, fn)
+		}
+	}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func anyIn(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if set[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DotEnvName is loaded automatically from the suite directory.
+const DotEnvName = ".env"
+
+// envSource resolves `${env:NAME}` and `{env: NAME}`: the OS environment
+// first, then the suite's env file.
+type envSource map[string]string
+
+func (e envSource) lookup(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := e[name]
+	return value, ok
+}
+
+// loadEnvFile reads explicit, or the .env next to suitePath when explicit
+// is empty. A missing automatic .env is not an error.
+func loadEnvFile(suitePath string, explicit string) (envSource, error) {
+	path := explicit
+	if path == "" {
+		path = filepath.Join(filepath.Dir(suitePath), DotEnvName)
+	}
+	values, err := readDotEnv(path)
+	if explicit == "" && errors.Is(err, os.ErrNotExist) {
+		return envSource{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("env file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// readDotEnv parses KEY=VALUE lines. Blank lines and # comments are skipped,
+// an `export ` prefix is allowed and values may be single or double quoted.
+func readDotEnv(path string) (envSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	out := envSource{}
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value", lineNo)
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return nil, fmt.Errorf("line %d: invalid quoted value", lineNo)
+			}
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		out[key] = value
+	}
+	return out, scanner.Err()
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/utils"
+	"github.com/DevrajJain04/reqres/internal/yamlmini"
+)
+
+// Format rewrites a suite file in canonical style: keys in schema order,
+// keys that repeat their default dropped, short maps and lists inline and
+// comments kept. It refuses to return output that would load differently.
+func Format(data []byte) ([]byte, error) {
+	docs, err := yamlmini.ParseSource(data)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		canonicalize(doc, suiteSchema)
+		parts = append(parts, yamlmini.Marshal(doc))
+	}
+	out := []byte(strings.Join(parts, "---\n"))
+	if err := sameContent(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func canonicalize(n *yamlmini.Node, f *field) {
+	if n == nil || n.Alias != "" {
+		return
+	}
+	if f == nil {
+		f = &field{}
+	}
+	switch {
+	case f.Kind == objectKind && n.Kind == yamlmini.MapNode:
+		n.Flow = false
+		if f.Prune && canPrune(n) {
+			pruneDefaults(n, f)
+		}
+		sortEntries(n, f)
+		for _, entry := range n.Entries {
+			if child := lookupField(f.Fields, entry.Key); child != nil {
+				canonicalize(entry.Value, child)
+			}
+		}
+	case f.Kind == mapKind && n.Kind == yamlmini.MapNode:
+		n.Flow = f.Inline && n.FitsFlow()
+		for _, entry := range n.Entries {
+			canonicalize(entry.Value, f.Elem)
+		}
+	case f.Kind == listKind && n.Kind == yamlmini.ListNode:
+		n.Flow = false
+		for _, item := range n.Items {
+			canonicalize(item, f.Elem)
+		}
+	case f.Kind == anyKind || f.Kind == scalarKind:
+		layout(n, f.Inline)
+	}
+}
+
+// layout styles free-form values such as bodies: short collections inline
+// where the field allows it, everything else as blocks.
+func layout(n *yamlmini.Node, inline bool) {
+	if n == nil || n.Alias != "" || n.Kind == yamlmini.ScalarNode {
+		return
+	}
+	n.Flow = inline && n.FitsFlow()
+	if n.Flow {
+		return
+	}
+	for _, entry := range n.Entries {
+		layout(entry.Value, inline)
+	}
+	for _, item := range n.Items {
+		layout(item, inline)
+	}
+}
+
+// canPrune reports whether dropping a default from n is safe: not when n
+// overrides a template or merge source, or is itself a merge source.
+func canPrune(n *yamlmini.Node) bool {
+	return n.Anchor == "" && n.Get("extends") == nil && !hasMerge(n)
+}
+
+func hasMerge(n *yamlmini.Node) bool {
+	for _, entry := range n.Entries {
+		if entry.Merge {
+			return true
+		}
+	}
+	return false
+}
+
+func pruneDefaults(n *yamlmini.Node, f *field) {
+	kept := n.Entries[:0:0]
+	for _, entry := range n.Entries {
+		child := lookupField(f.Fields, entry.Key)
+		if child != nil && entry.LineComment == "" && len(entry.HeadComment) == 0 && isDefault(entry.Value.Interface(), child) &&
+			entry.Value.Anchor == "" && entry.Value.Alias == "" {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	n.Entries = kept
+}
+
+func isDefault(value any, f *field) bool {
+	if f.Default == nil || value == nil {
+		return false
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(utils.ToString(value)), utils.ToString(f.Default))
+}
+
+// sortEntries orders keys as the schema lists them; `<<` and `x-` extension
+// keys stay first, since they usually hold anchors used further down, and
+// unknown keys keep their order at the end.
+func sortEntries(n *yamlmini.Node, f *field) {
+	rank := func(entry *yamlmini.Entry) int {
+		key := entry.Key
+		if entry.Merge || f.Extensions && strings.HasPrefix(key, extensionPrefix) {
+			return -1
+		}
+		for i, child := range f.Fields {
+			if child.Key == key {
+				return i
+			}
+		}
+		return len(f.Fields)
+	}
+	sorted := make([]*yamlmini.Entry, 0, len(n.Entries))
+	for r := -1; r <= len(f.Fields); r++ {
+		for _, entry := range n.Entries {
+			if rank(entry) == r {
+				sorted = append(sorted, entry)
+			}
+		}
+	}
+	n.Entries = sorted
+}
+
+// sameContent checks that before and after parse to the same suites once
+// defaults are filled in on both sides.
+func sameContent(before, after []byte) error {
+	want, err := yamlmini.ParseDocuments(before)
+	if err != nil {
+		return err
+	}
+	got, err := yamlmini.ParseDocuments(after)
+	if err != nil {
+		return fmt.Errorf("formatted output does not parse: %w", err)
+	}
+	if len(want) != len(got) {
+		return fmt.Errorf("formatted output has %d documents, expected %d", len(got), len(want))
+	}
+	for i := range want {
+		a, b := want[i].Interface(), got[i].Interface()
+		fillDefaults(a, suiteSchema)
+		fillDefaults(b, suiteSchema)
+		if !reflect.DeepEqual(a, b) {
+			return fmt.Errorf("formatted output of document %d does not match the input; leaving the file unchanged", i+1)
+		}
+	}
+	return nil
+}
+
+func fillDefaults(value any, f *field) {
+	if f == nil {
+		return
+	}
+	switch t := value.(type) {
+	case map[string]any:
+		switch f.Kind {
+		case objectKind:
+			if _, extends := t["extends"]; f.Prune && !extends {
+				for _, child := range f.Fields {
+					if child.Default == nil {
+						continue
+					}
+					if current, ok := t[child.Key]; !ok || isDefault(current, child) {
+						t[child.Key] = child.Default
+					}
+				}
+			}
+			for key, v := range t {
+				fillDefaults(v, lookupField(f.Fields, key))
+			}
+		case mapKind:
+			for _, v := range t {
+				fillDefaults(v, f.Elem)
+			}
+		}
+	case []any:
+		if f.Kind == listKind {
+			for _, item := range t {
+				fillDefaults(item, f.Elem)
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/yamlmini"
+)
+
+// resolveIncludes merges the files listed under `include:` into node.
+// Later includes override earlier ones and the including file overrides
+// them all; the top-level `tests` lists are concatenated with included tests
+// first. A file reached through several includes is merged only the first
+// time; done records the files merged so far. It returns the merged node and
+// every file that was pulled in.
+func resolveIncludes(node *yamlmini.Node, path string, stack []string, done map[string]bool) (*yamlmini.Node, []string, error) {
+	raw := node.Delete("include")
+	if raw == nil {
+		return node, nil, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	stack = append(stack, abs)
+
+	names := includeNames(raw)
+	var merged *yamlmini.Node
+	var files []string
+	for _, item := range names {
+		name, ok := item.Value.(string)
+		if item.Kind != yamlmini.ScalarNode || !ok || strings.TrimSpace(name) == "" {
+			return nil, nil, fmt.Errorf("%s: include must be a file path or a list of file paths", item.Pos)
+		}
+		includePath := name
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+		included, nested, err := loadInclude(includePath, stack, done)
+		if err != nil {
+			return nil, nil, fmt.Errorf("include %s: %w", includePath, err)
+		}
+		files = append(files, includePath)
+		files = append(files, nested...)
+		merged = mergeNodes(merged, included, true)
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("%s: include must be a file path or a list of file paths", raw.Pos)
+	}
+	return mergeNodes(merged, node, true), files, nil
+}
+
+func includeNames(raw *yamlmini.Node) []*yamlmini.Node {
+	switch {
+	case raw == nil:
+		return nil
+	case raw.Kind == yamlmini.ListNode:
+		return raw.Items
+	case raw.Kind == yamlmini.ScalarNode && raw.Value != nil:
+		return []*yamlmini.Node{raw}
+	}
+	return nil
+}
+
+// SourceFiles lists the files loading path reads, as far as they can be
+// found without loading it: path, its env file and every include, followed
+// recursively. Files that cannot be read or parsed are listed but not
+// followed, so a suite that does not load yet can still be watched.
+func SourceFiles(path string, opts LoadOptions) []string {
+	envFile := opts.EnvFile
+	if envFile == "" {
+		envFile = filepath.Join(filepath.Dir(path), DotEnvName)
+	}
+	files := []string{path, envFile}
+	seen := map[string]bool{}
+	var walk func(file string)
+	walk = func(file string) {
+		abs, err := filepath.Abs(file)
+		if err != nil || seen[abs] {
+			return
+		}
+		seen[abs] = true
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return
+		}
+		docs, err := yamlmini.ParseDocuments(content)
+		if err != nil {
+			return
+		}
+		for _, doc := range docs {
+			for _, item := range includeNames(doc.Get("include")) {
+				name, ok := item.Value.(string)
+				if !ok || strings.TrimSpace(name) == "" {
+					continue
+				}
+				if !filepath.IsAbs(name) {
+					name = filepath.Join(filepath.Dir(file), name)
+				}
+				files = append(files, name)
+				walk(name)
+			}
+		}
+	}
+	walk(path)
+	return uniqueSorted(files)
+}
+
+func loadInclude(path string, stack []string, done map[string]bool) (*yamlmini.Node, []string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if i := slices.Index(stack, abs); i >= 0 {
+		// Name files relative to the root suite so equal base names in
+		// different directories stay distinguishable.
+		root := filepath.Dir(stack[0])
+		chain := append(slices.Clone(stack[i:]), abs)
+		for j := range chain {
+			if rel, err := filepath.Rel(root, chain[j]); err == nil {
+				chain[j] = filepath.ToSlash(rel)
+			}
+		}
+		return nil, nil, fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+	}
+	if done[abs] {
+		return nil, nil, nil
+	}
+	done[abs] = true
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := yamlmini.ParseNode(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if node.Kind != yamlmini.MapNode {
+		return nil, nil, fmt.Errorf("root must be a map")
+	}
+	node.SetFile(path)
+	return resolveIncludes(node, path, stack, done)
+}
+
+// mergeNodes overlays over on base without modifying either. Maps merge key
+// by key and everything else is replaced, except that at the root of a suite
+// the `tests` lists are concatenated.
+func mergeNodes(base, over *yamlmini.Node, root bool) *yamlmini.Node {
+	switch {
+	case base == nil:
+		return over
+	case over == nil:
+		return base
+	case base.Kind == yamlmini.MapNode && over.Kind == yamlmini.MapNode:
+		out := &yamlmini.Node{Kind: yamlmini.MapNode, Pos: over.Pos}
+		for _, entry := range base.Entries {
+			value := entry.Value
+			pos := entry.Pos
+			if overValue := over.Get(entry.Key); overValue != nil {
+				if root && entry.Key == "tests" && value.Kind == yamlmini.ListNode && overValue.Kind == yamlmini.ListNode {
+					value = &yamlmini.Node{Kind: yamlmini.ListNode, Pos: overValue.Pos, Items: append(slices.Clone(value.Items), overValue.Items...)}
+				} else {
+					value = mergeNodes(value, overValue, false)
+				}
+				pos = entryPos(over, entry.Key)
+			}
+			out.Entries = append(out.Entries, &yamlmini.Entry{Key: entry.Key, Pos: pos, Value: value, Merge: entry.Merge})
+		}
+		for _, entry := range over.Entries {
+			if base.Get(entry.Key) == nil {
+				out.Entries = append(out.Entries, entry)
+			}
+		}
+		return out
+	default:
+		return over
+	}
+}
+
+func entryPos(n *yamlmini.Node, key string) yamlmini.Pos {
+	for _, entry := range n.Entries {
+		if entry.Key == key {
+			return entry.Pos
+		}
+	}
+	return n.Pos
+}
+
+// applyTemplates expands `extends: <name>` on tests using the top-level
+// `templates:` map. Keys set on the test win; maps such as headers merge.
+// Templates may extend other templates.
+func applyTemplates(root *yamlmini.Node) error {
+	templates := root.Get("templates")
+	tests := root.Get("tests")
+	if tests == nil || tests.Kind != yamlmini.ListNode {
+		return nil
+	}
+	resolved := map[string]*yamlmini.Node{}
+	var resolve func(name string, pos yamlmini.Pos, seen []string) (*yamlmini.Node, error)
+	resolve = func(name string, pos yamlmini.Pos, seen []string) (*yamlmini.Node, error) {
+		if node, ok := resolved[name]; ok {
+			return node, nil
+		}
+		if slices.Contains(seen, name) {
+			return nil, fmt.Errorf("%s: template cycle: %s -> %s", pos, strings.Join(seen, " -> "), name)
+		}
+		template := templates.Get(name)
+		if template == nil || template.Kind != yamlmini.MapNode {
+			return nil, fmt.Errorf("%s: unknown template %q", pos, name)
+		}
+		node, err := extend(template, append(seen, name), resolve)
+		if err != nil {
+			return nil, err
+		}
+		resolved[name] = node
+		return node, nil
+	}
+
+	for i, test := range tests.Items {
+		if test.Kind != yamlmini.MapNode {
+			continue
+		}
+		node, err := extend(test, nil, resolve)
+		if err != nil {
+			return err
+		}
+		tests.Items[i] = node
+	}
+	return nil
+}
+
+func extend(node *yamlmini.Node, seen []string, resolve func(string, yamlmini.Pos, []string) (*yamlmini.Node, error)) (*yamlmini.Node, error) {
+	parent := node.Get("extends")
+	if parent == nil {
+		return node, nil
+	}
+	name, ok := parent.Value.(string)
+	if parent.Kind != yamlmini.ScalarNode || !ok || name == "" {
+		return nil, fmt.Errorf("%s: extends must be a template name", parent.Pos)
+	}
+	base, err := resolve(name, parent.Pos, seen)
+	if err != nil {
+		return nil, err
+	}
+	own := &yamlmini.Node{Kind: yamlmini.MapNode, Pos: node.Pos, Entries: slices.Clone(node.Entries)}
+	own.Delete("extends")
+	return mergeNodes(base, own, false), nil
+}
@@ -4,37 +4,159 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
 	"github.com/DevrajJain04/reqres/internal/utils"
 	"github.com/DevrajJain04/reqres/internal/yamlmini"
 )
 
-func LoadFromFile(path string, env string) (model.Config, error) {
+// LoadOptions selects an env block and extra variable sources.
+type LoadOptions struct {
+	Env string
+	// EnvFile replaces the .env file next to the suite.
+	EnvFile string
+	// Vars come from --var and override every other source.
+	Vars map[string]string
+}
+
+// LoadFromFile loads a single-document suite.
+func LoadFromFile(path string, opts LoadOptions) (model.Config, error) {
+	configs, err := LoadAllFromFile(path, opts)
+	if err != nil {
+		return model.Config{}, err
+	}
+	if len(configs) != 1 {
+		return model.Config{}, fmt.Errorf("config %s: expected one suite, found %d documents", path, len(configs))
+	}
+	return configs[0], nil
+}
+
+// LoadAllFromFile loads every `---` separated suite in path. Each config's
+// File is the document label used in reports (see DocumentLabel).
+//
+// Variables are layered as: --var > env file > envs.<name> > vars.
+func LoadAllFromFile(path string, opts LoadOptions) ([]model.Config, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return model.Config{}, fmt.Errorf("read config %s: %w", path, err)
+		return nil, fmt.Errorf("read config %s: %w", path, err)
 	}
-	decoded, err := yamlmini.Parse(content)
+	docs, err := yamlmini.ParseDocuments(content)
 	if err != nil {
-		return model.Config{}, fmt.Errorf("parse config %s: %w", path, err)
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
 	}
-	root, ok := decoded.(map[string]any)
-	if !ok {
-		return model.Config{}, fmt.Errorf("config %s: root must be a map", path)
+	if len(docs) == 0 {
+		docs = []*yamlmini.Node{{Kind: yamlmini.MapNode}}
+	}
+	env, err := loadEnvFile(path, opts.EnvFile)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	configs := make([]model.Config, 0, len(docs))
+	for i, node := range docs {
+		label := path
+		if len(docs) > 1 {
+			label = DocumentLabel(path, i)
+		}
+		cfg, err := loadDocument(node, path, opts, env)
+		if err != nil {
+			if Located(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("config %s: %w", label, err)
+		}
+		cfg.File = label
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+var locatedRE = regexp.MustCompile(`^\S.*?:\d+:\d+: `)
+
+// Located reports whether err starts with a file:line:col position, so
+// callers need not name the file again.
+func Located(err error) bool {
+	return locatedRE.MatchString(err.Error())
+}
+
+// DocumentLabel names the index-th (0-based) document of a multi-document file.
+func DocumentLabel(path string, index int) string {
+	return fmt.Sprintf("%s#%d", path, index+1)
+}
+
+// SourceFile strips the document suffix added by DocumentLabel.
+func SourceFile(label string) string {
+	if i := strings.LastIndex(label, "#"); i >= 0 {
+		if _, err := strconv.Atoi(label[i+1:]); err == nil {
+			return label[:i]
+		}
+	}
+	return label
+}
+
+// CleanLabel normalizes a suite label so that the same file matches however
+// it was named on the command line: relative to the working directory when
+// possible, otherwise cleaned, with any document suffix kept.
+func CleanLabel(label string) string {
+	file := SourceFile(label)
+	suffix := label[len(file):]
+	if abs, err := filepath.Abs(file); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				return rel + suffix
+			}
+		}
+	}
+	return filepath.Clean(file) + suffix
+}
+
+func loadDocument(node *yamlmini.Node, path string, opts LoadOptions, env envSource) (model.Config, error) {
+	if node.Kind != yamlmini.MapNode {
+		return model.Config{}, fmt.Errorf("root must be a map")
+	}
+	node.SetFile(path)
+	node, includes, err := resolveIncludes(node, path, nil, map[string]bool{})
+	if err != nil {
+		return model.Config{}, err
 	}
+	unknown := unknownKeys(node)
+	if err := applyTemplates(node); err != nil {
+		return model.Config{}, err
+	}
+	warnings := expandEnvRefs(node, env)
+	root := node.Interface().(map[string]any)
+	positions := convertPositions(node.Positions())
 
-	cfg, err := decodeConfig(root)
+	cfg, err := decodeConfig(root, filepath.Dir(path), positions, env)
 	if err != nil {
-		return model.Config{}, fmt.Errorf("config %s: %w", path, err)
+		return model.Config{}, err
 	}
+	cfg.Positions = positions
+	cfg.Includes = includes
+	cfg.DataFiles = dataFiles(root, path, opts)
+	cfg.Warnings = warnings
+	cfg.UnknownKeys = unknown
 
-	if env != "" {
-		if err := applyEnv(&cfg, env); err != nil {
-			return model.Config{}, fmt.Errorf("config %s: %w", path, err)
+	if opts.Env != "" {
+		if err := applyEnv(&cfg, opts.Env, filepath.Dir(path), env); err != nil {
+			return model.Config{}, err
 		}
 	}
+	for k, v := range env {
+		cfg.Vars[k] = v
+	}
+	for k, v := range opts.Vars {
+		cfg.Vars[k] = v
+	}
+
+	for _, name := range cfg.Secrets {
+		secrets.Add(utils.ToString(cfg.Vars[name]))
+	}
 
 	// Keep timeout and retries predictable for runner internals.
 	if cfg.Timeout <= 0 {
@@ -63,18 +185,34 @@ func LoadFromFile(path string, env string) (model.Config, error) {
 	return cfg, nil
 }
 
-func decodeConfig(root map[string]any) (model.Config, error) {
+func decodeConfig(root map[string]any, baseDir string, positions map[string]model.Position, env envSource) (model.Config, error) {
 	cfg := model.Config{
-		Base:     utils.ToString(root["base"]),
-		Timeout:  utils.ToInt(root["timeout"], 5000),
-		Retries:  utils.ToInt(root["retries"], 0),
-		Vars:     utils.ToStringMap(root["vars"]),
-		Defaults: decodeDefaults(root["defaults"]),
-		Envs:     decodeEnvs(root["envs"]),
-		Load:     decodeLoad(root["load"]),
-		Mock:     decodeMockConfig(root["mock"]),
-	}
-	tests, err := decodeTests(root["tests"])
+		Base:     utils.ToString(suiteSchema.value(root, "base")),
+		Timeout:  utils.ToInt(suiteSchema.value(root, "timeout"), suiteSchema.intDefault("timeout")),
+		Retries:  utils.ToInt(suiteSchema.value(root, "retries"), suiteSchema.intDefault("retries")),
+		Deadline: strings.TrimSpace(utils.ToString(suiteSchema.value(root, "deadline"))),
+		Vars:     utils.ToStringMap(suiteSchema.value(root, "vars")),
+		Defaults: decodeDefaults(suiteSchema.value(root, "defaults")),
+		Load:     decodeLoad(suiteSchema.value(root, "load")),
+		Mock:     decodeMockConfig(suiteSchema.value(root, "mock")),
+	}
+	secretVars, err := decodeSecrets(suiteSchema.value(root, "secrets"), baseDir, "secrets", env)
+	if err != nil {
+		return model.Config{}, err
+	}
+	for name, value := range secretVars {
+		cfg.Vars[name] = value
+		cfg.Secrets = append(cfg.Secrets, name)
+	}
+	cfg.Envs = decodeEnvs(suiteSchema.value(root, "envs"))
+	for _, override := range cfg.Envs {
+		for name := range override.Secrets {
+			cfg.Secrets = append(cfg.Secrets, name)
+		}
+	}
+	cfg.Secrets = uniqueSorted(cfg.Secrets)
+
+	tests, err := decodeTests(suiteSchema.value(root, "tests"), positions)
 	if err != nil {
 		return model.Config{}, err
 	}
@@ -82,11 +220,49 @@ func decodeConfig(root map[string]any) (model.Config, error) {
 	return cfg, nil
 }
 
+// expandEnvRefs resolves `${env:...}` in every string up front and returns a
+// warning for each reference that is unset and has no fallback. Those stay
+// in place and fail when a test actually uses them.
+func expandEnvRefs(node *yamlmini.Node, env envSource) []string {
+	var warnings []string
+	seen := map[string]bool{}
+	var walk func(n *yamlmini.Node)
+	walk = func(n *yamlmini.Node) {
+		if text, ok := n.Value.(string); ok {
+			expanded, unset := utils.ExpandEnv(text, env.lookup)
+			n.Value = expanded
+			for _, name := range unset {
+				warning := fmt.Sprintf("%s: environment variable %s is not set", n.Pos, name)
+				if !seen[warning] {
+					seen[warning] = true
+					warnings = append(warnings, warning)
+				}
+			}
+		}
+		for _, entry := range n.Entries {
+			walk(entry.Value)
+		}
+		for _, item := range n.Items {
+			walk(item)
+		}
+	}
+	walk(node)
+	return warnings
+}
+
+func convertPositions(in map[string]yamlmini.Pos) map[string]model.Position {
+	out := make(map[string]model.Position, len(in))
+	for path, pos := range in {
+		out[path] = model.Position{File: pos.File, Line: pos.Line, Col: pos.Col}
+	}
+	return out
+}
+
 func decodeDefaults(raw any) model.Defaults {
 	data := utils.ToStringMap(raw)
 	return model.Defaults{
-		Headers: utils.ToStringStringMap(data["headers"]),
-		Auth:    utils.ToString(data["auth"]),
+		Headers: utils.ToStringStringMap(defaultsSchema.value(data, "headers")),
+		Auth:    utils.ToString(defaultsSchema.value(data, "auth")),
 	}
 }
 
@@ -96,19 +272,20 @@ func decodeEnvs(raw any) map[string]model.EnvOverride {
 	for name, value := range root {
 		data := utils.ToStringMap(value)
 		override := model.EnvOverride{
-			Base: utils.ToString(data["base"]),
-			Vars: utils.ToStringMap(data["vars"]),
+			Base:    utils.ToString(envSchema.value(data, "base")),
+			Vars:    utils.ToStringMap(envSchema.value(data, "vars")),
+			Secrets: utils.ToStringMap(envSchema.value(data, "secrets")),
 		}
-		if _, ok := data["timeout"]; ok {
-			v := utils.ToInt(data["timeout"], 0)
+		if _, ok := envSchema.lookup(data, "timeout"); ok {
+			v := utils.ToInt(envSchema.value(data, "timeout"), 0)
 			override.Timeout = &v
 		}
-		if _, ok := data["retries"]; ok {
-			v := utils.ToInt(data["retries"], 0)
+		if _, ok := envSchema.lookup(data, "retries"); ok {
+			v := utils.ToInt(envSchema.value(data, "retries"), 0)
 			override.Retries = &v
 		}
-		if _, ok := data["defaults"]; ok {
-			d := decodeDefaults(data["defaults"])
+		if _, ok := envSchema.lookup(data, "defaults"); ok {
+			d := decodeDefaults(envSchema.value(data, "defaults"))
 			override.Defaults = &d
 		}
 		out[name] = override
@@ -116,35 +293,119 @@ func decodeEnvs(raw any) map[string]model.EnvOverride {
 	return out
 }
 
-func decodeTests(raw any) ([]model.TestCase, error) {
+// dataFiles lists the env file (whether or not it exists yet) and the
+// `{file: ...}` secret sources of the suite and the selected env.
+func dataFiles(root map[string]any, path string, opts LoadOptions) []string {
+	files := []string{opts.EnvFile}
+	if opts.EnvFile == "" {
+		files[0] = filepath.Join(filepath.Dir(path), DotEnvName)
+	}
+	blocks := []any{root["secrets"]}
+	if opts.Env != "" {
+		override := utils.ToStringMap(utils.ToStringMap(root["envs"])[opts.Env])
+		blocks = append(blocks, override["secrets"])
+	}
+	for _, block := range blocks {
+		for _, value := range utils.ToStringMap(block) {
+			source, ok := value.(map[string]any)
+			if !ok || source["file"] == nil {
+				continue
+			}
+			file := utils.ToString(source["file"])
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			files = append(files, file)
+		}
+	}
+	return uniqueSorted(files)
+}
+
+// decodeSecrets resolves a `secrets:` block. Each entry is either a literal
+// value or a source map: `{env: NAME}` reads the OS environment (or the env
+// file) and `{file: path}` reads a file relative to the suite.
+func decodeSecrets(raw any, baseDir string, location string, env envSource) (map[string]any, error) {
+	data := utils.ToStringMap(raw)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	out := map[string]any{}
+	for name, value := range data {
+		source, isSource := value.(map[string]any)
+		if !isSource {
+			out[name] = value
+			continue
+		}
+		switch {
+		case source["env"] != nil:
+			envName := utils.ToString(source["env"])
+			envValue, ok := env.lookup(envName)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s: environment variable %s is not set", location, name, envName)
+			}
+			out[name] = envValue
+		case source["file"] != nil:
+			filePath := utils.ToString(source["file"])
+			if !filepath.IsAbs(filePath) {
+				filePath = filepath.Join(baseDir, filePath)
+			}
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: read secret file: %w", location, name, err)
+			}
+			out[name] = strings.TrimRight(string(content), "\r\n")
+		default:
+			return nil, fmt.Errorf("%s.%s must be a value, {env: NAME} or {file: path}", location, name)
+		}
+	}
+	return out, nil
+}
+
+func uniqueSorted(items []string) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	sort.Strings(items)
+	out := items[:1]
+	for _, item := range items[1:] {
+		if item != out[len(out)-1] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func decodeTests(raw any, positions map[string]model.Position) ([]model.TestCase, error) {
 	rows := utils.ToSlice(raw)
 	out := make([]model.TestCase, 0, len(rows))
 	for idx, row := range rows {
+		location := fmt.Sprintf("tests[%d]", idx)
 		testMap := utils.ToStringMap(row)
 		if len(testMap) == 0 {
-			return nil, fmt.Errorf("tests[%d] must be a map", idx)
+			return nil, errorAt(positions, location, "%s must be a map", location)
 		}
 		test := model.TestCase{
-			Name:     utils.ToString(testMap["name"]),
-			Method:   strings.ToUpper(utils.ToString(testMap["method"])),
-			Path:     utils.ToString(testMap["path"]),
-			Headers:  utils.ToStringStringMap(testMap["headers"]),
-			Query:    utils.ToStringMap(testMap["query"]),
-			Body:     testMap["body"],
-			Auth:     utils.ToString(testMap["auth"]),
-			Tags:     decodeTags(testMap["tags"]),
-			Check:    testMap["check"],
-			Capture:  decodeCapture(testMap["capture"]),
-			After:    utils.ToString(testMap["after"]),
-			Snapshot: testMap["snapshot"],
-			Mock:     decodeMockRoute(testMap["mock"]),
-		}
-		if _, ok := testMap["retries"]; ok {
-			v := utils.ToInt(testMap["retries"], 0)
+			Name:     utils.ToString(testSchema.value(testMap, "name")),
+			Method:   strings.ToUpper(utils.ToString(testSchema.value(testMap, "method"))),
+			Path:     utils.ToString(testSchema.value(testMap, "path")),
+			Headers:  utils.ToStringStringMap(testSchema.value(testMap, "headers")),
+			Query:    utils.ToStringMap(testSchema.value(testMap, "query")),
+			Body:     testSchema.value(testMap, "body"),
+			Auth:     utils.ToString(testSchema.value(testMap, "auth")),
+			Tags:     decodeTags(testSchema.value(testMap, "tags")),
+			Check:    testSchema.value(testMap, "check"),
+			Capture:  decodeCapture(testSchema.value(testMap, "capture")),
+			After:    utils.ToString(testSchema.value(testMap, "after")),
+			Snapshot: testSchema.value(testMap, "snapshot"),
+			Mock:     decodeMockRoute(testSchema.value(testMap, "mock")),
+			Pos:      positions[location],
+		}
+		if _, ok := testSchema.lookup(testMap, "retries"); ok {
+			v := utils.ToInt(testSchema.value(testMap, "retries"), 0)
 			test.Retries = &v
 		}
-		if _, ok := testMap["timeout"]; ok {
-			v := utils.ToInt(testMap["timeout"], 0)
+		if _, ok := testSchema.lookup(testMap, "timeout"); ok {
+			v := utils.ToInt(testSchema.value(testMap, "timeout"), 0)
 			test.TimeoutMS = &v
 		}
 		out = append(out, test)
@@ -194,16 +455,16 @@ func decodeLoad(raw any) *model.LoadConfig {
 		return nil
 	}
 	return &model.LoadConfig{
-		Users:    utils.ToInt(data["users"], 1),
-		Duration: utils.ToString(data["duration"]),
-		RampUp:   utils.ToString(data["ramp_up"]),
-		Method:   strings.ToUpper(utils.ToString(data["method"])),
-		Path:     utils.ToString(data["path"]),
-		Query:    utils.ToStringMap(data["query"]),
-		Headers:  utils.ToStringStringMap(data["headers"]),
-		Body:     data["body"],
-		Check:    data["check"],
-		Tags:     decodeTags(data["tags"]),
+		Users:    utils.ToInt(loadSchema.value(data, "users"), loadSchema.intDefault("users")),
+		Duration: utils.ToString(loadSchema.value(data, "duration")),
+		RampUp:   utils.ToString(loadSchema.value(data, "ramp_up")),
+		Method:   strings.ToUpper(utils.ToString(loadSchema.value(data, "method"))),
+		Path:     utils.ToString(loadSchema.value(data, "path")),
+		Query:    utils.ToStringMap(loadSchema.value(data, "query")),
+		Headers:  utils.ToStringStringMap(loadSchema.value(data, "headers")),
+		Body:     loadSchema.value(data, "body"),
+		Check:    loadSchema.value(data, "check"),
+		Tags:     decodeTags(loadSchema.value(data, "tags")),
 	}
 }
 
@@ -215,7 +476,7 @@ func decodeMockConfig(raw any) *model.MockConfig {
 	if len(data) == 0 {
 		return nil
 	}
-	rows := utils.ToSlice(data["routes"])
+	rows := utils.ToSlice(mockSchema.value(data, "routes"))
 	routes := make([]model.MockRoute, 0, len(rows))
 	for _, row := range rows {
 		route := decodeMockRoute(row)
@@ -225,7 +486,7 @@ func decodeMockConfig(raw any) *model.MockConfig {
 	}
 	return &model.MockConfig{
 		Routes: routes,
-		Delay:  utils.ToString(data["delay"]),
+		Delay:  utils.ToString(mockSchema.value(data, "delay")),
 	}
 }
 
@@ -235,18 +496,18 @@ func decodeMockRoute(raw any) *model.MockRoute {
 		return nil
 	}
 	return &model.MockRoute{
-		Name:    utils.ToString(data["name"]),
-		Method:  strings.ToUpper(utils.ToString(data["method"])),
-		Path:    utils.ToString(data["path"]),
-		Status:  utils.ToInt(data["status"], 200),
-		Headers: utils.ToStringStringMap(data["headers"]),
-		Body:    data["body"],
-		Query:   utils.ToStringMap(data["query"]),
-		Delay:   utils.ToString(data["delay"]),
+		Name:    utils.ToString(mockRouteSchema.value(data, "name")),
+		Method:  strings.ToUpper(utils.ToString(mockRouteSchema.value(data, "method"))),
+		Path:    utils.ToString(mockRouteSchema.value(data, "path")),
+		Status:  utils.ToInt(mockRouteSchema.value(data, "status"), mockRouteSchema.intDefault("status")),
+		Headers: utils.ToStringStringMap(mockRouteSchema.value(data, "headers")),
+		Body:    mockRouteSchema.value(data, "body"),
+		Query:   utils.ToStringMap(mockRouteSchema.value(data, "query")),
+		Delay:   utils.ToString(mockRouteSchema.value(data, "delay")),
 	}
 }
 
-func applyEnv(cfg *model.Config, env string) error {
+func applyEnv(cfg *model.Config, env string, baseDir string, source envSource) error {
 	override, ok := cfg.Envs[env]
 	if !ok {
 		available := make([]string, 0, len(cfg.Envs))
@@ -270,6 +531,18 @@ func applyEnv(cfg *model.Config, env string) error {
 		}
 		cfg.Vars[k] = v
 	}
+	// Env secrets are resolved only for the selected env so unrelated envs
+	// may reference variables that are not set on this machine.
+	secretVars, err := decodeSecrets(override.Secrets, baseDir, "envs."+env+".secrets", source)
+	if err != nil {
+		return err
+	}
+	for k, v := range secretVars {
+		if cfg.Vars == nil {
+			cfg.Vars = map[string]any{}
+		}
+		cfg.Vars[k] = v
+	}
 	if override.Defaults != nil {
 		if cfg.Defaults.Headers == nil {
 			cfg.Defaults.Headers = map[string]string{}
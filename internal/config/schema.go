@@ -0,0 +1,289 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/utils"
+	"github.com/DevrajJain04/reqres/internal/yamlmini"
+)
+
+// extensionPrefix marks keys reqres ignores where a field allows Extensions.
+const extensionPrefix = "x-"
+
+type fieldKind int
+
+const (
+	// anyKind values are free-form and not checked further.
+	anyKind fieldKind = iota
+	scalarKind
+	objectKind
+	mapKind
+	listKind
+)
+
+// field describes one key of the suite format: an object lists its keys in
+// Fields, a map or list describes its values in Elem. The decoder reads keys
+// through value, unknown-key detection walks Fields, `reqres schema` renders
+// the same table and `reqres fmt` orders keys by it, so they cannot disagree.
+type field struct {
+	Key         string
+	Kind        fieldKind
+	Types       []string
+	Description string
+	Default     any
+	Enum        []any
+	Fields      []*field
+	Elem        *field
+	// Inline lets fmt write short collections as `{...}` / `[...]`.
+	Inline bool
+	// Prune lets fmt drop keys of this object that repeat their Default.
+	Prune bool
+	// Extensions allows `x-` keys in this object, e.g. to hold anchors.
+	Extensions bool
+}
+
+var (
+	stringType      = []string{"string"}
+	integerType     = []string{"integer"}
+	scalarTypes     = []string{"string", "number", "boolean"}
+	stringListTypes = []string{"string", "array"}
+
+	methodEnum = []any{
+		"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS",
+		"get", "post", "put", "patch", "delete", "head", "options",
+	}
+
+	headersField = func(description string) *field {
+		return &field{Key: "headers", Kind: mapKind, Description: description, Inline: true,
+			Elem: &field{Kind: scalarKind, Types: scalarTypes}}
+	}
+	queryField = &field{Key: "query", Kind: mapKind, Description: "Query string parameters.", Inline: true, Elem: &field{Inline: true}}
+
+	defaultsSchema = &field{Key: "defaults", Kind: objectKind, Description: "Headers and auth applied to every test.", Fields: []*field{
+		headersField("Headers sent with every request; a test's own headers win."),
+		{Key: "auth", Kind: scalarKind, Types: stringType, Description: "Authorization header value used when a test sets none."},
+	}}
+
+	secretsSchema = &field{Key: "secrets", Kind: mapKind, Description: "Variables whose values are masked in output. Each value is a literal, {env: NAME} or {file: path}.",
+		Inline: true, Elem: &field{Kind: anyKind, Inline: true}}
+
+	envSchema = &field{Kind: objectKind, Description: "Overrides applied with --env <name>.", Fields: []*field{
+		{Key: "base", Kind: scalarKind, Types: stringType, Description: "Base URL for this environment."},
+		{Key: "timeout", Kind: scalarKind, Types: integerType, Description: "Request timeout in milliseconds."},
+		{Key: "retries", Kind: scalarKind, Types: integerType, Description: "Retries per test."},
+		{Key: "vars", Kind: mapKind, Description: "Variables for this environment.", Inline: true, Elem: &field{Inline: true}},
+		secretsSchema,
+		defaultsSchema,
+	}}
+
+	mockRouteSchema = &field{Kind: objectKind, Description: "A route served by `reqres mock`.", Prune: true, Fields: []*field{
+		{Key: "name", Kind: scalarKind, Types: stringType, Description: "Route name."},
+		{Key: "method", Kind: scalarKind, Types: stringType, Enum: methodEnum, Default: "GET", Description: "HTTP method to match."},
+		{Key: "path", Kind: scalarKind, Types: stringType, Description: "Path to match."},
+		{Key: "status", Kind: scalarKind, Types: integerType, Default: 200, Description: "Response status code."},
+		headersField("Response headers."),
+		{Key: "query", Kind: mapKind, Description: "Query parameters the request must carry.", Inline: true, Elem: &field{Inline: true}},
+		{Key: "body", Description: "Response body.", Inline: true},
+		{Key: "delay", Kind: scalarKind, Types: stringType, Description: "Response delay, e.g. 200ms."},
+	}}
+
+	testSchema = &field{Kind: objectKind, Description: "One HTTP request and its checks.", Prune: true, Fields: []*field{
+		{Key: "name", Kind: scalarKind, Types: stringType, Description: "Unique test name, referenced by `after`."},
+		{Key: "method", Kind: scalarKind, Types: stringType, Enum: methodEnum, Default: "GET", Description: "HTTP method."},
+		{Key: "path", Kind: scalarKind, Types: stringType, Description: "Request path, joined to `base`."},
+		{Key: "extends", Kind: scalarKind, Types: stringType, Description: "Template from `templates` this test builds on."},
+		{Key: "after", Kind: scalarKind, Types: stringType, Description: "Name of the test that must pass first."},
+		{Key: "auth", Kind: scalarKind, Types: stringType, Description: "Authorization header value."},
+		headersField("Request headers."),
+		queryField,
+		{Key: "body", Description: "Request body, sent as JSON unless it is a string.", Inline: true},
+		{Key: "tags", Types: stringListTypes, Description: "Tags for --tags filtering, as a list or comma-separated string.", Inline: true},
+		{Key: "capture", Kind: mapKind, Description: "Variables to capture from the response body by JSONPath.", Inline: true, Elem: &field{Kind: scalarKind, Types: stringType}},
+		{Key: "check", Types: []string{"integer", "string", "object"}, Default: 200, Description: "Expected status code, or a map of status, headers, body and $.path checks."},
+		{Key: "snapshot", Types: []string{"boolean", "string"}, Description: "Compare the response body to a stored snapshot; a string names the snapshot file."},
+		{Key: "mock", Kind: objectKind, Description: "Mock route served for this test by `reqres mock`.", Prune: true, Fields: mockRouteSchema.Fields},
+		{Key: "retries", Kind: scalarKind, Types: integerType, Description: "Retries for this test."},
+		{Key: "timeout", Kind: scalarKind, Types: integerType, Description: "Timeout for this test in milliseconds."},
+	}}
+
+	loadSchema = &field{Key: "load", Kind: objectKind, Description: "Load test run after the functional tests.", Fields: []*field{
+		{Key: "users", Kind: scalarKind, Types: integerType, Default: 1, Description: "Concurrent virtual users."},
+		{Key: "duration", Kind: scalarKind, Types: stringType, Description: "How long to run, e.g. 30s."},
+		{Key: "ramp_up", Kind: scalarKind, Types: stringType, Description: "Time to start all users, e.g. 5s."},
+		{Key: "method", Kind: scalarKind, Types: stringType, Enum: methodEnum, Default: "GET", Description: "HTTP method."},
+		{Key: "path", Kind: scalarKind, Types: stringType, Description: "Request path."},
+		headersField("Request headers."),
+		queryField,
+		{Key: "body", Description: "Request body.", Inline: true},
+		{Key: "check", Types: []string{"integer", "string", "object"}, Description: "Check each response must pass to count as a success."},
+		{Key: "tags", Types: stringListTypes, Description: "Run the load block only when these tags are selected.", Inline: true},
+	}}
+
+	mockSchema = &field{Key: "mock", Kind: objectKind, Description: "Routes served by `reqres mock`.", Fields: []*field{
+		{Key: "routes", Kind: listKind, Description: "Mock routes.", Elem: mockRouteSchema},
+		{Key: "delay", Kind: scalarKind, Types: stringType, Description: "Default delay for every route, e.g. 100ms."},
+	}}
+
+	suiteSchema = &field{Kind: objectKind, Description: "A reqres test suite.", Extensions: true, Fields: []*field{
+		{Key: "include", Types: stringListTypes, Description: "Files merged into this suite; this file wins.", Inline: true},
+		{Key: "base", Kind: scalarKind, Types: stringType, Description: "Base URL every test path is joined to."},
+		{Key: "timeout", Kind: scalarKind, Types: integerType, Default: 5000, Description: "Request timeout in milliseconds."},
+		{Key: "retries", Kind: scalarKind, Types: integerType, Default: 0, Description: "Retries per test."},
+		{Key: "deadline", Kind: scalarKind, Types: stringType, Description: "Maximum wall time of the suite, e.g. 2m; tests still running then fail as timed out."},
+		{Key: "vars", Kind: mapKind, Description: "Variables available as ${name}.", Inline: true, Elem: &field{Inline: true}},
+		secretsSchema,
+		defaultsSchema,
+		{Key: "envs", Kind: mapKind, Description: "Named environment overrides, selected with --env.", Elem: envSchema},
+		{Key: "templates", Kind: mapKind, Description: "Named test fragments used with `extends`.", Elem: testSchema},
+		loadSchema,
+		mockSchema,
+		{Key: "tests", Kind: listKind, Description: "Tests to run.", Elem: testSchema},
+	}}
+)
+
+// value returns data[key]. Reading a key the schema does not declare is a
+// programming error, which keeps the decoder and the schema in sync.
+func (f *field) value(data map[string]any, key string) any {
+	value, _ := f.lookup(data, key)
+	return value
+}
+
+func (f *field) lookup(data map[string]any, key string) (any, bool) {
+	if lookupField(f.Fields, key) == nil {
+		panic(fmt.Sprintf("config: key %q is missing from the schema", key))
+	}
+	value, ok := data[key]
+	return value, ok
+}
+
+// intDefault returns the schema default for key.
+func (f *field) intDefault(key string) int {
+	child := lookupField(f.Fields, key)
+	if child == nil {
+		panic(fmt.Sprintf("config: key %q is missing from the schema", key))
+	}
+	return utils.ToInt(child.Default, 0)
+}
+
+// unknownKeys reports every map key in node that the suite format does not
+// define, with a suggestion when the key looks like a typo.
+func unknownKeys(node *yamlmini.Node) []string {
+	var out []string
+	var walk func(n *yamlmini.Node, schema *field, path string)
+	walk = func(n *yamlmini.Node, schema *field, path string) {
+		if n == nil || schema == nil {
+			return
+		}
+		switch {
+		case schema.Kind == objectKind && n.Kind == yamlmini.MapNode:
+			known := make([]string, len(schema.Fields))
+			for i, f := range schema.Fields {
+				known[i] = f.Key
+			}
+			for _, entry := range n.Entries {
+				child := lookupField(schema.Fields, entry.Key)
+				if child == nil && schema.Extensions && strings.HasPrefix(entry.Key, extensionPrefix) {
+					continue
+				}
+				if child == nil {
+					out = append(out, unknownKeyMessage(entry, path, known))
+					continue
+				}
+				walk(entry.Value, child, joinPath(path, entry.Key))
+			}
+		case schema.Kind == mapKind && n.Kind == yamlmini.MapNode:
+			for _, entry := range n.Entries {
+				walk(entry.Value, schema.Elem, joinPath(path, entry.Key))
+			}
+		case schema.Kind == listKind && n.Kind == yamlmini.ListNode:
+			for i, item := range n.Items {
+				walk(item, schema.Elem, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+	walk(node, suiteSchema, "")
+	return out
+}
+
+func unknownKeyMessage(entry *yamlmini.Entry, path string, known []string) string {
+	where := "at top level"
+	if path != "" {
+		where = "in " + path
+	}
+	message := fmt.Sprintf("%s: unknown key %q %s", entry.Pos, entry.Key, where)
+	if suggestion := utils.Suggest(entry.Key, known); suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return message
+}
+
+func lookupField(fields []*field, key string) *field {
+	for _, f := range fields {
+		if f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// JSONSchema describes the suite format as a JSON Schema (draft-07) for
+// editors such as yaml-language-server.
+func JSONSchema() map[string]any {
+	out := jsonSchemaFor(suiteSchema)
+	out["$schema"] = "http://json-schema.org/draft-07/schema#"
+	out["title"] = "reqres suite"
+	return out
+}
+
+func jsonSchemaFor(f *field) map[string]any {
+	out := map[string]any{}
+	if f.Description != "" {
+		out["description"] = f.Description
+	}
+	if f.Default != nil {
+		out["default"] = f.Default
+	}
+	if len(f.Enum) > 0 {
+		out["enum"] = f.Enum
+	}
+	switch f.Kind {
+	case objectKind:
+		out["type"] = "object"
+		properties := map[string]any{}
+		for _, child := range f.Fields {
+			properties[child.Key] = jsonSchemaFor(child)
+		}
+		out["properties"] = properties
+		out["additionalProperties"] = false
+		if f.Extensions {
+			out["patternProperties"] = map[string]any{"^" + extensionPrefix: map[string]any{}}
+		}
+	case mapKind:
+		out["type"] = "object"
+		if f.Elem != nil {
+			out["additionalProperties"] = jsonSchemaFor(f.Elem)
+		}
+	case listKind:
+		out["type"] = "array"
+		if f.Elem != nil {
+			out["items"] = jsonSchemaFor(f.Elem)
+		}
+	default:
+		switch len(f.Types) {
+		case 0:
+		case 1:
+			out["type"] = f.Types[0]
+		default:
+			out["type"] = f.Types
+		}
+	}
+	return out
+}
@@ -9,65 +9,101 @@ import (
 
 func Validate(cfg model.Config) []error {
 	var errs []error
+	fail := func(path string, format string, args ...any) {
+		errs = append(errs, errorAt(cfg.Positions, path, format, args...))
+	}
 	if strings.TrimSpace(cfg.Base) == "" {
-		errs = append(errs, fmt.Errorf("base is required"))
+		fail("base", "base is required")
 	}
 	if cfg.Timeout <= 0 {
-		errs = append(errs, fmt.Errorf("timeout must be > 0"))
+		fail("timeout", "timeout must be > 0")
 	}
 	if cfg.Retries < 0 {
-		errs = append(errs, fmt.Errorf("retries must be >= 0"))
+		fail("retries", "retries must be >= 0")
 	}
 
 	nameSeen := map[string]struct{}{}
 	for i, test := range cfg.Tests {
 		location := fmt.Sprintf("tests[%d]", i)
 		if strings.TrimSpace(test.Name) == "" {
-			errs = append(errs, fmt.Errorf("%s.name is required", location))
+			fail(location+".name", "%s.name is required", location)
 		} else {
 			if _, ok := nameSeen[test.Name]; ok {
-				errs = append(errs, fmt.Errorf("duplicate test name %q", test.Name))
+				fail(location+".name", "duplicate test name %q", test.Name)
 			}
 			nameSeen[test.Name] = struct{}{}
 		}
 		if strings.TrimSpace(test.Path) == "" {
-			errs = append(errs, fmt.Errorf("%s.path is required", location))
+			fail(location+".path", "%s.path is required", location)
 		}
 		if test.Retries != nil && *test.Retries < 0 {
-			errs = append(errs, fmt.Errorf("%s.retries must be >= 0", location))
+			fail(location+".retries", "%s.retries must be >= 0", location)
 		}
 		if test.TimeoutMS != nil && *test.TimeoutMS <= 0 {
-			errs = append(errs, fmt.Errorf("%s.timeout must be > 0", location))
+			fail(location+".timeout", "%s.timeout must be > 0", location)
 		}
 	}
 
-	for _, test := range cfg.Tests {
+	for i, test := range cfg.Tests {
 		if test.After == "" {
 			continue
 		}
 		if _, ok := nameSeen[test.After]; !ok {
-			errs = append(errs, fmt.Errorf("test %q depends on unknown test %q", test.Name, test.After))
+			fail(fmt.Sprintf("tests[%d].after", i), "test %q depends on unknown test %q", test.Name, test.After)
 		}
 	}
 
 	if cfg.Load != nil {
 		if cfg.Load.Users <= 0 {
-			errs = append(errs, fmt.Errorf("load.users must be > 0"))
+			fail("load.users", "load.users must be > 0")
 		}
 		if strings.TrimSpace(cfg.Load.Duration) == "" {
-			errs = append(errs, fmt.Errorf("load.duration is required"))
+			fail("load.duration", "load.duration is required")
 		}
 	}
 
 	if cfg.Mock != nil {
 		for i, route := range cfg.Mock.Routes {
+			location := fmt.Sprintf("mock.routes[%d]", i)
 			if strings.TrimSpace(route.Path) == "" {
-				errs = append(errs, fmt.Errorf("mock.routes[%d].path is required", i))
+				fail(location+".path", "%s.path is required", location)
 			}
 			if route.Status <= 0 {
-				errs = append(errs, fmt.Errorf("mock.routes[%d].status must be > 0", i))
+				fail(location+".status", "%s.status must be > 0", location)
 			}
 		}
 	}
+
+	analyze(cfg, fail)
 	return errs
 }
+
+// errorAt prefixes the message with the source position of path, falling back
+// to the closest enclosing key when path itself is absent (e.g. a missing key).
+func errorAt(positions map[string]model.Position, path string, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	if pos, ok := lookupPosition(positions, path); ok {
+		return fmt.Errorf("%s: %s", pos, message)
+	}
+	return fmt.Errorf("%s", message)
+}
+
+func lookupPosition(positions map[string]model.Position, path string) (model.Position, bool) {
+	if len(positions) == 0 {
+		return model.Position{}, false
+	}
+	for {
+		if pos, ok := positions[path]; ok {
+			return pos, true
+		}
+		if path == "" {
+			return model.Position{}, false
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			path = ""
+			continue
+		}
+		path = path[:cut]
+	}
+}
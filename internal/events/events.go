@@ -0,0 +1,149 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
+)
+
+const (
+	RunStart     = "run_start"
+	FileStart    = "file_start"
+	TestStart    = "test_start"
+	TestEnd      = "test_end"
+	FileEnd      = "file_end"
+	LoadProgress = "load_progress"
+	RunEnd       = "run_end"
+)
+
+// Event is one line of the NDJSON stream. Only the fields relevant to the
+// event type are set.
+type Event struct {
+	Type       string            `json:"type"`
+	Time       time.Time         `json:"time"`
+	File       string            `json:"file,omitempty"`
+	Files      []string          `json:"files,omitempty"`
+	Test       string            `json:"test,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	Status     model.TestStatus  `json:"status,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	StatusCode int               `json:"status_code,omitempty"`
+	DurationMS int64             `json:"duration_ms,omitempty"`
+	Attempts   int               `json:"attempts,omitempty"`
+	Captures   map[string]string `json:"captures,omitempty"`
+	Total      int               `json:"total,omitempty"`
+	Passed     int               `json:"passed,omitempty"`
+	Failed     int               `json:"failed,omitempty"`
+	Skipped    int               `json:"skipped,omitempty"`
+	Cancelled  int               `json:"cancelled,omitempty"`
+	Flaky      []string          `json:"flaky,omitempty"`
+	Load       *LoadStats        `json:"load,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// MarshalJSON writes every count of file_end and run_end, the total of
+// file_start and the duration of test_end even when they are zero, so consumers can tell "0 failures" from
+// a missing field.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plain Event
+	switch {
+	case e.Type == FileStart:
+		return json.Marshal(struct {
+			plain
+			Total int `json:"total"`
+		}{plain(e), e.Total})
+	case e.Type == TestEnd:
+		return json.Marshal(struct {
+			plain
+			DurationMS int64 `json:"duration_ms"`
+		}{plain(e), e.DurationMS})
+	case e.Type == FileEnd || e.Type == RunEnd && e.Error == "":
+		return json.Marshal(struct {
+			plain
+			Total      int   `json:"total"`
+			Passed     int   `json:"passed"`
+			Failed     int   `json:"failed"`
+			Skipped    int   `json:"skipped"`
+			Cancelled  int   `json:"cancelled"`
+			DurationMS int64 `json:"duration_ms"`
+		}{plain(e), e.Total, e.Passed, e.Failed, e.Skipped, e.Cancelled, e.DurationMS})
+	}
+	return json.Marshal(plain(e))
+}
+
+type LoadStats struct {
+	ElapsedMS int64 `json:"elapsed_ms"`
+	Requests  int64 `json:"requests"`
+	Successes int64 `json:"successes"`
+	Failures  int64 `json:"failures"`
+}
+
+type Sink interface {
+	Emit(Event)
+}
+
+// Nop discards every event.
+type Nop struct{}
+
+func (Nop) Emit(Event) {}
+
+// OrNop returns sink, or a Nop sink when sink is nil.
+func OrNop(sink Sink) Sink {
+	if sink == nil {
+		return Nop{}
+	}
+	return sink
+}
+
+// NDJSON writes one JSON object per line. It is safe for concurrent use.
+type NDJSON struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewNDJSON(w io.Writer) *NDJSON {
+	return &NDJSON{enc: json.NewEncoder(w)}
+}
+
+func (n *NDJSON) Emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.File = secrets.Redact(event.File)
+	event.Test = secrets.Redact(event.Test)
+	event.Path = secrets.Redact(event.Path)
+	event.Message = secrets.Redact(event.Message)
+	event.Error = secrets.Redact(event.Error)
+	if event.Captures != nil {
+		captures := map[string]string{}
+		for k, v := range event.Captures {
+			captures[k] = secrets.Redact(v)
+		}
+		event.Captures = captures
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_ = n.enc.Encode(event)
+}
+
+// Result builds a test_end event from a finished test.
+func Result(file string, result model.TestResult) Event {
+	return Event{
+		Type:       TestEnd,
+		File:       file,
+		Test:       result.Name,
+		Method:     result.Method,
+		Path:       result.Path,
+		Status:     result.Status,
+		Message:    result.Message,
+		StatusCode: result.StatusCode,
+		DurationMS: result.DurationMS,
+		Attempts:   result.Attempts,
+		Captures:   result.Captures,
+	}
+}
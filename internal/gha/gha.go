@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/secrets"
 )
 
 func Enabled(flag bool) bool {
@@ -23,15 +25,39 @@ func EscapeAnnotation(value string) string {
 	return value
 }
 
-func FailureAnnotation(file, test, message string) string {
+// FailureAnnotation formats an ::error command. A line > 0 pins the
+// annotation to the failing test's `- name:` line.
+func FailureAnnotation(file string, line int, test, message string) string {
+	file = secrets.Redact(file)
+	test = secrets.Redact(test)
+	message = secrets.Redact(message)
 	title := EscapeAnnotation(fmt.Sprintf("ReqRes %s", test))
 	msg := EscapeAnnotation(message)
 	if strings.TrimSpace(file) != "" {
+		if line > 0 {
+			return fmt.Sprintf("::error file=%s,line=%d,title=%s::%s", EscapeAnnotation(file), line, title, msg)
+		}
 		return fmt.Sprintf("::error file=%s,title=%s::%s", EscapeAnnotation(file), title, msg)
 	}
 	return fmt.Sprintf("::error title=%s::%s", title, msg)
 }
 
+// WriteStepSummary appends markdown to the job summary file GitHub provides
+// through $GITHUB_STEP_SUMMARY. It is a no-op outside GitHub Actions.
+func WriteStepSummary(markdown string) error {
+	path := strings.TrimSpace(os.Getenv("GITHUB_STEP_SUMMARY"))
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(secrets.Redact(markdown) + "\n")
+	return err
+}
+
 func WriteWorkflow(path string) error {
 	if strings.TrimSpace(path) == "" {
 		path = filepath.Join(".github", "workflows", "reqres.yml")
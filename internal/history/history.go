@@ -0,0 +1,237 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
+)
+
+const (
+	DefaultPath      = ".reqres_history.jsonl"
+	DefaultWindow    = 20
+	DefaultThreshold = 0.3
+)
+
+// Entry is one line of the append-only history file.
+type Entry struct {
+	StartedAt  time.Time   `json:"started_at"`
+	DurationMS int64       `json:"duration_ms"`
+	Tests      []TestEntry `json:"tests"`
+}
+
+type TestEntry struct {
+	File       string           `json:"file"`
+	Name       string           `json:"name"`
+	Status     model.TestStatus `json:"status"`
+	DurationMS int64            `json:"duration_ms"`
+}
+
+type Stats struct {
+	File       string
+	Name       string
+	Runs       int
+	Passed     int
+	Failed     int
+	PassRate   float64
+	Flakiness  float64
+	AvgMS      float64
+	Durations  []int64
+	LastStatus model.TestStatus
+}
+
+func (s Stats) Key() string {
+	return s.File + "::" + s.Name
+}
+
+func Append(path string, data model.RunReport) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	data = secrets.RedactReport(data)
+	entry := Entry{
+		StartedAt:  data.StartedAt,
+		DurationMS: data.DurationMS,
+	}
+	for _, file := range data.Files {
+		for _, test := range file.Tests {
+			entry.Tests = append(entry.Tests, TestEntry{
+				File:       file.File,
+				Name:       test.Name,
+				Status:     test.Status,
+				DurationMS: test.DurationMS,
+			})
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	out := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			return nil, fmt.Errorf("history %s line %d: %w", path, lineNo, err)
+		}
+		out = append(out, entry)
+	}
+	return out, scanner.Err()
+}
+
+// Analyze computes per-test stats over the last `window` runs.
+//
+// The flakiness score is the share of consecutive runs in which the outcome
+// flipped between pass and fail; a run already marked flaky counts as a flip.
+// Skipped and cancelled runs are ignored. 0 means stable, 1 means it flips every run.
+func Analyze(entries []Entry, window int) []Stats {
+	if window > 0 && len(entries) > window {
+		entries = entries[len(entries)-window:]
+	}
+	byKey := map[string]*Stats{}
+	outcomes := map[string][]model.TestStatus{}
+	for _, entry := range entries {
+		for _, test := range entry.Tests {
+			key := test.File + "::" + test.Name
+			stats, ok := byKey[key]
+			if !ok {
+				stats = &Stats{File: test.File, Name: test.Name}
+				byKey[key] = stats
+			}
+			stats.LastStatus = test.Status
+			if test.Status == model.StatusSkip || test.Status == model.StatusCancelled {
+				continue
+			}
+			stats.Runs++
+			stats.Durations = append(stats.Durations, test.DurationMS)
+			if test.Status == model.StatusPass {
+				stats.Passed++
+			} else {
+				stats.Failed++
+			}
+			outcomes[key] = append(outcomes[key], test.Status)
+		}
+	}
+
+	out := make([]Stats, 0, len(byKey))
+	for key, stats := range byKey {
+		if stats.Runs > 0 {
+			stats.PassRate = float64(stats.Passed) / float64(stats.Runs)
+			sum := int64(0)
+			for _, d := range stats.Durations {
+				sum += d
+			}
+			stats.AvgMS = float64(sum) / float64(len(stats.Durations))
+		}
+		stats.Flakiness = flakiness(outcomes[key])
+		out = append(out, *stats)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Flakiness != out[j].Flakiness {
+			return out[i].Flakiness > out[j].Flakiness
+		}
+		return out[i].Key() < out[j].Key()
+	})
+	return out
+}
+
+// Scores maps file::test keys to flakiness scores at or above threshold.
+func Scores(entries []Entry, window int, threshold float64) map[string]float64 {
+	out := map[string]float64{}
+	for _, stats := range Analyze(entries, window) {
+		if stats.Runs > 1 && stats.Flakiness >= threshold {
+			out[stats.Key()] = stats.Flakiness
+		}
+	}
+	return out
+}
+
+func flakiness(statuses []model.TestStatus) float64 {
+	if len(statuses) < 2 {
+		return 0
+	}
+	flips := 0
+	for i, status := range statuses {
+		if status == model.StatusFlaky {
+			flips++
+			continue
+		}
+		if i > 0 && passed(statuses[i-1]) != passed(status) && statuses[i-1] != model.StatusFlaky {
+			flips++
+		}
+	}
+	score := float64(flips) / float64(len(statuses)-1)
+	if score > 1 {
+		score = 1
+	}
+	return score
+}
+
+func passed(status model.TestStatus) bool {
+	return status == model.StatusPass
+}
+
+// Trend renders durations as a compact sparkline, oldest first.
+func Trend(durations []int64) string {
+	if len(durations) == 0 {
+		return ""
+	}
+	levels := []rune("▁▂▃▄▅▆▇█")
+	lo, hi := durations[0], durations[0]
+	for _, d := range durations {
+		if d < lo {
+			lo = d
+		}
+		if d > hi {
+			hi = d
+		}
+	}
+	out := make([]rune, 0, len(durations))
+	for _, d := range durations {
+		idx := 0
+		if hi > lo {
+			idx = int(float64(d-lo) / float64(hi-lo) * float64(len(levels)-1))
+		}
+		out = append(out, levels[idx])
+	}
+	return string(out)
+}
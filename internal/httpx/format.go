@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Curl renders the request as an equivalent curl command for a POSIX shell.
+func (p Prepared) Curl() string {
+	var b strings.Builder
+	b.WriteString("curl")
+	switch {
+	case p.Method == "HEAD":
+		b.WriteString(" --head")
+	case p.Method != "GET" || p.Body != nil:
+		b.WriteString(" -X " + p.Method)
+	}
+	b.WriteString(" " + shellQuote(p.URL))
+	for _, line := range headerLines(p) {
+		b.WriteString(" \\\n  -H " + shellQuote(line))
+	}
+	if p.Body != nil {
+		b.WriteString(" \\\n  --data-raw " + shellQuote(string(p.Body)))
+	}
+	return b.String()
+}
+
+// HTTP renders the request as a raw HTTP/1.1 request block.
+func (p Prepared) HTTP() string {
+	target, host := p.URL, ""
+	if parsed, err := url.Parse(p.URL); err == nil && parsed.Host != "" {
+		target, host = parsed.RequestURI(), parsed.Host
+	}
+	var b strings.Builder
+	b.WriteString(p.Method + " " + target + " HTTP/1.1\n")
+	if host != "" {
+		b.WriteString("Host: " + host + "\n")
+	}
+	for _, line := range headerLines(p) {
+		b.WriteString(line + "\n")
+	}
+	if p.Body != nil {
+		b.WriteString("\n" + string(p.Body) + "\n")
+	}
+	return b.String()
+}
+
+func headerLines(p Prepared) []string {
+	keys := make([]string, 0, len(p.Header))
+	for key := range p.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range p.Header[key] {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	return lines
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -31,20 +31,59 @@ type Response struct {
 	BodyBytes  []byte
 	BodyJSON   any
 	BodyText   string
+	Request    Prepared
 }
 
-func Do(opts RequestOptions) (Response, error) {
+// Prepared is a fully resolved request: query, default headers and auth applied.
+type Prepared struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+func Prepare(opts RequestOptions) (Prepared, error) {
 	reqURL, err := addQuery(opts.URL, opts.Query)
 	if err != nil {
-		return Response{}, err
+		return Prepared{}, err
 	}
 
-	bodyReader, bodyContentType, err := requestBody(opts.Body)
+	body, bodyContentType, err := requestBody(opts.Body)
+	if err != nil {
+		return Prepared{}, err
+	}
+
+	header := http.Header{}
+	for key, value := range opts.Headers {
+		header.Set(key, value)
+	}
+	if header.Get("Accept") == "" {
+		header.Set("Accept", "application/json")
+	}
+	if bodyContentType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", bodyContentType)
+	}
+	if err := applyAuth(header, opts.Auth); err != nil {
+		return Prepared{}, err
+	}
+
+	return Prepared{
+		Method: strings.ToUpper(opts.Method),
+		URL:    reqURL,
+		Header: header,
+		Body:   body,
+	}, nil
+}
+
+// Do sends the request, giving up when ctx is cancelled. The returned
+// Response carries the prepared request even when sending fails so callers
+// can report what was attempted.
+func Do(ctx context.Context, opts RequestOptions) (Response, error) {
+	prepared, err := Prepare(opts)
 	if err != nil {
 		return Response{}, err
 	}
 
-	ctx := context.Background()
 	timeout := opts.Timeout
 	if timeout <= 0 {
 		timeout = 5 * time.Second
@@ -52,35 +91,26 @@ func Do(opts RequestOptions) (Response, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(opts.Method), reqURL, bodyReader)
-	if err != nil {
-		return Response{}, err
-	}
-
-	for key, value := range opts.Headers {
-		req.Header.Set(key, value)
-	}
-	if req.Header.Get("Accept") == "" {
-		req.Header.Set("Accept", "application/json")
+	var bodyReader io.Reader
+	if prepared.Body != nil {
+		bodyReader = bytes.NewReader(prepared.Body)
 	}
-	if bodyContentType != "" && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", bodyContentType)
-	}
-
-	if err := applyAuth(req, opts.Auth); err != nil {
-		return Response{}, err
+	req, err := http.NewRequestWithContext(ctx, prepared.Method, prepared.URL, bodyReader)
+	if err != nil {
+		return Response{Request: prepared}, err
 	}
+	req.Header = prepared.Header.Clone()
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return Response{}, err
+		return Response{Request: prepared}, err
 	}
 	defer resp.Body.Close()
 
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return Response{}, err
+		return Response{Request: prepared}, err
 	}
 	bodyText := string(respBytes)
 	bodyJSON := decodeJSON(respBytes)
@@ -91,6 +121,7 @@ func Do(opts RequestOptions) (Response, error) {
 		BodyBytes:  respBytes,
 		BodyText:   bodyText,
 		BodyJSON:   bodyJSON,
+		Request:    prepared,
 	}, nil
 }
 
@@ -117,25 +148,25 @@ func addQuery(rawURL string, query map[string]any) (string, error) {
 	return parsed.String(), nil
 }
 
-func requestBody(body any) (io.Reader, string, error) {
+func requestBody(body any) ([]byte, string, error) {
 	if body == nil {
 		return nil, "", nil
 	}
 	switch t := body.(type) {
 	case string:
-		return strings.NewReader(t), "application/json", nil
+		return []byte(t), "application/json", nil
 	case []byte:
-		return bytes.NewReader(t), "application/json", nil
+		return t, "application/json", nil
 	default:
 		raw, err := json.Marshal(t)
 		if err != nil {
 			return nil, "", fmt.Errorf("marshal request body: %w", err)
 		}
-		return bytes.NewReader(raw), "application/json", nil
+		return raw, "application/json", nil
 	}
 }
 
-func applyAuth(req *http.Request, auth string) error {
+func applyAuth(header http.Header, auth string) error {
 	value := strings.TrimSpace(auth)
 	if value == "" {
 		return nil
@@ -146,11 +177,11 @@ func applyAuth(req *http.Request, auth string) error {
 	}
 	switch strings.ToLower(strings.TrimSpace(parts[0])) {
 	case "bearer":
-		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(parts[1]))
+		header.Set("Authorization", "Bearer "+strings.TrimSpace(parts[1]))
 	case "basic":
 		credentials := strings.TrimSpace(parts[1])
 		encoded := base64.StdEncoding.EncodeToString([]byte(credentials))
-		req.Header.Set("Authorization", "Basic "+encoded)
+		header.Set("Authorization", "Basic "+encoded)
 	default:
 		return fmt.Errorf("unsupported auth scheme %q", parts[0])
 	}
@@ -1,6 +1,7 @@
 package loadtest
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/DevrajJain04/reqres/internal/assertion"
+	"github.com/DevrajJain04/reqres/internal/events"
 	"github.com/DevrajJain04/reqres/internal/httpx"
 	"github.com/DevrajJain04/reqres/internal/model"
 	"github.com/DevrajJain04/reqres/internal/utils"
@@ -20,9 +22,14 @@ type Options struct {
 	Auth      string
 	TimeoutMS int
 	Retries   int
+	// File labels load_progress events; Events receives one per second.
+	File   string
+	Events events.Sink
 }
 
-func Run(loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
+// Run drives the load phase until its duration is over or ctx is cancelled,
+// in which case the summary covers the requests made so far.
+func Run(ctx context.Context, loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
 	method := strings.ToUpper(strings.TrimSpace(loadCfg.Method))
 	if method == "" {
 		method = "GET"
@@ -45,12 +52,42 @@ func Run(loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
 	var successes int64
 	var failures int64
 	latencies := []float64{}
+	samples := []sample{}
 	var latencyMu sync.Mutex
 
 	users := loadCfg.Users
 	if users <= 0 {
 		users = 1
 	}
+	sink := events.OrNop(opts.Events)
+	progress := func() {
+		sink.Emit(events.Event{
+			Type:   events.LoadProgress,
+			File:   opts.File,
+			Method: method,
+			Path:   loadCfg.Path,
+			Load: &events.LoadStats{
+				ElapsedMS: time.Since(start).Milliseconds(),
+				Requests:  atomic.LoadInt64(&requests),
+				Successes: atomic.LoadInt64(&successes),
+				Failures:  atomic.LoadInt64(&failures),
+			},
+		})
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				progress()
+			}
+		}
+	}()
+
 	var wg sync.WaitGroup
 	for i := 0; i < users; i++ {
 		wg.Add(1)
@@ -58,12 +95,16 @@ func Run(loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
 			defer wg.Done()
 			if rampUp > 0 && users > 1 {
 				delay := time.Duration(float64(rampUp) * (float64(workerID) / float64(users-1)))
-				time.Sleep(delay)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
 			}
-			for time.Now().Before(stopAt) {
+			for time.Now().Before(stopAt) && ctx.Err() == nil {
 				startReq := time.Now()
 				resp, err := withRetries(opts.Retries, func() (httpx.Response, error) {
-					return httpx.Do(httpx.RequestOptions{
+					return httpx.Do(ctx, httpx.RequestOptions{
 						Method:  method,
 						URL:     joinURL(opts.BaseURL, loadCfg.Path),
 						Headers: opts.Headers,
@@ -73,18 +114,27 @@ func Run(loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
 						Timeout: time.Duration(opts.TimeoutMS) * time.Millisecond,
 					})
 				})
-				elapsed := float64(time.Since(startReq).Milliseconds())
+				if ctx.Err() != nil {
+					// Aborted requests say nothing about the target.
+					return
+				}
+				elapsed := float64(time.Since(startReq)) / float64(time.Millisecond)
+				failed := err != nil
+				if !failed {
+					failed = assertion.Evaluate(defaultCheck(loadCfg.Check), resp.StatusCode, resp.Headers, resp.BodyJSON) != nil
+				}
 
 				latencyMu.Lock()
 				latencies = append(latencies, elapsed)
+				samples = append(samples, sample{
+					second:  int(time.Since(start) / time.Second),
+					latency: elapsed,
+					failed:  failed,
+				})
 				latencyMu.Unlock()
 
 				atomic.AddInt64(&requests, 1)
-				if err != nil {
-					atomic.AddInt64(&failures, 1)
-					continue
-				}
-				if err := assertion.Evaluate(defaultCheck(loadCfg.Check), resp.StatusCode, resp.Headers, resp.BodyJSON); err != nil {
+				if failed {
 					atomic.AddInt64(&failures, 1)
 					continue
 				}
@@ -93,6 +143,8 @@ func Run(loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
 		}(i)
 	}
 	wg.Wait()
+	close(done)
+	progress()
 
 	f := summarizeLatencies(latencies)
 	summary := &model.LoadSummary{
@@ -107,6 +159,8 @@ func Run(loadCfg model.LoadConfig, opts Options) (*model.LoadSummary, error) {
 		MinMS:      f.min,
 		MaxMS:      f.max,
 		DurationMS: time.Since(start).Milliseconds(),
+		Timeline:   buildTimeline(samples),
+		Cancelled:  ctx.Err() != nil,
 	}
 	return summary, nil
 }
@@ -159,11 +213,55 @@ func summarizeLatencies(values []float64) latencyStats {
 	for _, value := range values {
 		sum += value
 	}
-	p95Index := int(float64(len(values)-1) * 0.95)
 	return latencyStats{
 		min: values[0],
 		max: values[len(values)-1],
 		avg: sum / float64(len(values)),
-		p95: values[p95Index],
+		p95: percentile(values, 0.95),
+	}
+}
+
+type sample struct {
+	second  int
+	latency float64
+	failed  bool
+}
+
+func buildTimeline(samples []sample) []model.LoadSample {
+	if len(samples) == 0 {
+		return nil
+	}
+	bySecond := map[int][]sample{}
+	last := 0
+	for _, item := range samples {
+		bySecond[item.second] = append(bySecond[item.second], item)
+		if item.second > last {
+			last = item.second
+		}
 	}
+	out := make([]model.LoadSample, 0, last+1)
+	for second := 0; second <= last; second++ {
+		bucket := bySecond[second]
+		point := model.LoadSample{Second: second}
+		latencies := make([]float64, 0, len(bucket))
+		for _, item := range bucket {
+			point.Requests++
+			if item.failed {
+				point.Failures++
+			}
+			latencies = append(latencies, item.latency)
+		}
+		if len(latencies) > 0 {
+			sort.Float64s(latencies)
+			point.P50MS = percentile(latencies, 0.50)
+			point.P95MS = percentile(latencies, 0.95)
+			point.P99MS = percentile(latencies, 0.99)
+		}
+		out = append(out, point)
+	}
+	return out
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	return sorted[int(float64(len(sorted)-1)*p)]
 }
@@ -1,17 +1,54 @@
 package model
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
+
+// Position is a 1-based location in a suite file.
+type Position struct {
+	File string
+	Line int
+	Col  int
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	loc := strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Col)
+	if p.File != "" {
+		return p.File + ":" + loc
+	}
+	return loc
+}
 
 type Config struct {
 	Base     string
 	Timeout  int
 	Retries  int
+	Deadline string // wall time cap for the suite, tests and load together
 	Vars     map[string]any
+	Secrets  []string
 	Defaults Defaults
 	Envs     map[string]EnvOverride
 	Load     *LoadConfig
 	Mock     *MockConfig
 	Tests    []TestCase
+	// File and Positions map config paths such as `tests[2].path` to their
+	// source location.
+	File      string
+	Positions map[string]Position
+	// Includes lists every file merged in through `include:`.
+	Includes []string
+	// DataFiles lists the other files loading depends on: the env file and
+	// `{file: ...}` secret sources.
+	DataFiles []string
+	// Warnings are non-fatal problems found while loading, shown by validate.
+	Warnings []string
+	// UnknownKeys lists keys the suite format does not define. They are
+	// warnings unless --strict is set.
+	UnknownKeys []string
 }
 
 type Defaults struct {
@@ -24,6 +61,7 @@ type EnvOverride struct {
 	Timeout  *int
 	Retries  *int
 	Vars     map[string]any
+	Secrets  map[string]any
 	Defaults *Defaults
 }
 
@@ -43,6 +81,7 @@ type TestCase struct {
 	Mock      *MockRoute
 	Retries   *int
 	TimeoutMS *int
+	Pos       Position
 }
 
 type LoadConfig struct {
@@ -76,14 +115,28 @@ type MockRoute struct {
 
 type RunOptions struct {
 	Env             string
+	EnvFile         string
+	Vars            map[string]string
+	Strict          bool
 	Tags            []string
 	Parallel        int
 	ReportJSONPath  string
 	ReportHTMLPath  string
+	ReportMDPath    string
 	GitHubActions   bool
 	DetectFlakyRuns int
 	UpdateSnapshots bool
 	RunLoad         bool
+	HistoryPath     string
+	FlakyThreshold  float64
+	// Only restricts a run to the named tests of each suite, keyed by suite
+	// label. Suites without an entry are not run. Nil runs everything.
+	Only map[string][]string
+	// MaxFailures stops the run after that many failing tests; 0 means no
+	// limit.
+	MaxFailures int
+	// Timeout caps the wall time of the whole run; 0 means no limit.
+	Timeout time.Duration
 }
 
 type RunReport struct {
@@ -94,32 +147,41 @@ type RunReport struct {
 	Passed         int            `json:"passed"`
 	Failed         int            `json:"failed"`
 	Skipped        int            `json:"skipped"`
+	Cancelled      int            `json:"cancelled,omitempty"`
 	Flaky          []string       `json:"flaky,omitempty"`
 	Files          []FileReport   `json:"files"`
 	Load           *LoadSummary   `json:"load,omitempty"`
 	Failures       []FailureEntry `json:"failures,omitempty"`
 	GeneratedBy    string         `json:"generated_by"`
 	SnapshotsSaved int            `json:"snapshots_saved,omitempty"`
+	// Seed is set when the run used random functions such as uuid().
+	Seed int64 `json:"seed,omitempty"`
+	// Stopped says why the run ended before every test finished.
+	Stopped string `json:"stopped,omitempty"`
 }
 
 type FileReport struct {
-	File     string       `json:"file"`
-	Total    int          `json:"total"`
-	Passed   int          `json:"passed"`
-	Failed   int          `json:"failed"`
-	Skipped  int          `json:"skipped"`
-	Duration int64        `json:"duration_ms"`
-	Tests    []TestResult `json:"tests"`
+	File      string       `json:"file"`
+	Total     int          `json:"total"`
+	Passed    int          `json:"passed"`
+	Failed    int          `json:"failed"`
+	Skipped   int          `json:"skipped"`
+	Cancelled int          `json:"cancelled,omitempty"`
+	Duration  int64        `json:"duration_ms"`
+	Tests     []TestResult `json:"tests"`
+	// Stopped says why the suite ended early on its own, e.g. its deadline.
+	Stopped string `json:"stopped,omitempty"`
 }
 
 type TestStatus string
 
 const (
-	StatusPass    TestStatus = "pass"
-	StatusFail    TestStatus = "fail"
-	StatusSkip    TestStatus = "skip"
-	StatusFlaky   TestStatus = "flaky"
-	StatusUnknown TestStatus = "unknown"
+	StatusPass      TestStatus = "pass"
+	StatusFail      TestStatus = "fail"
+	StatusSkip      TestStatus = "skip"
+	StatusFlaky     TestStatus = "flaky"
+	StatusCancelled TestStatus = "cancelled" // the run was interrupted first
+	StatusUnknown   TestStatus = "unknown"
 )
 
 type TestResult struct {
@@ -128,28 +190,66 @@ type TestResult struct {
 	Path       string            `json:"path"`
 	Status     TestStatus        `json:"status"`
 	Message    string            `json:"message,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	Line       int               `json:"line,omitempty"`
+	StartedAt  time.Time         `json:"started_at,omitzero"`
 	DurationMS int64             `json:"duration_ms"`
 	Attempts   int               `json:"attempts"`
 	StatusCode int               `json:"status_code,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	After      string            `json:"after,omitempty"`
 	Captures   map[string]string `json:"captures,omitempty"`
+	Request    *RequestDetail    `json:"request,omitempty"`
+	Response   *ResponseDetail   `json:"response,omitempty"`
+}
+
+type RequestDetail struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+type ResponseDetail struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	Truncated  bool              `json:"truncated,omitempty"`
 }
 
 type FailureEntry struct {
 	File string `json:"file"`
-	Test string `json:"test"`
-	Why  string `json:"why"`
+	// Source is the file that defines the test, which differs from File
+	// for tests pulled in with `include:` or from multi-document files.
+	Source string `json:"source,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Test   string `json:"test"`
+	Why    string `json:"why"`
 }
 
 type LoadSummary struct {
-	Method     string  `json:"method"`
-	Path       string  `json:"path"`
-	Users      int     `json:"users"`
-	Requests   int64   `json:"requests"`
-	Successes  int64   `json:"successes"`
-	Failures   int64   `json:"failures"`
-	AvgMS      float64 `json:"avg_ms"`
-	P95MS      float64 `json:"p95_ms"`
-	MinMS      float64 `json:"min_ms"`
-	MaxMS      float64 `json:"max_ms"`
-	DurationMS int64   `json:"duration_ms"`
+	Method     string       `json:"method"`
+	Path       string       `json:"path"`
+	Users      int          `json:"users"`
+	Requests   int64        `json:"requests"`
+	Successes  int64        `json:"successes"`
+	Failures   int64        `json:"failures"`
+	AvgMS      float64      `json:"avg_ms"`
+	P95MS      float64      `json:"p95_ms"`
+	MinMS      float64      `json:"min_ms"`
+	MaxMS      float64      `json:"max_ms"`
+	DurationMS int64        `json:"duration_ms"`
+	Timeline   []LoadSample `json:"timeline,omitempty"`
+	// Cancelled is set when the run was stopped before the load duration.
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// LoadSample aggregates the requests that finished within one second of a load run.
+type LoadSample struct {
+	Second   int     `json:"second"`
+	Requests int64   `json:"requests"`
+	Failures int64   `json:"failures"`
+	P50MS    float64 `json:"p50_ms"`
+	P95MS    float64 `json:"p95_ms"`
+	P99MS    float64 `json:"p99_ms"`
 }
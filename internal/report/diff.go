@@ -0,0 +1,323 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+// Threshold decides whether a latency change is significant. A percentage
+// threshold compares relative growth, a millisecond threshold absolute growth.
+type Threshold struct {
+	Percent float64
+	MS      float64
+}
+
+func ParseThreshold(raw string) (Threshold, error) {
+	value := strings.TrimSpace(raw)
+	switch {
+	case value == "":
+		return Threshold{Percent: 20}, nil
+	case strings.HasSuffix(value, "%"):
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil || n < 0 {
+			return Threshold{}, fmt.Errorf("invalid threshold %q", raw)
+		}
+		return Threshold{Percent: n}, nil
+	case strings.HasSuffix(value, "ms"):
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "ms"), 64)
+		if err != nil || n < 0 {
+			return Threshold{}, fmt.Errorf("invalid threshold %q", raw)
+		}
+		return Threshold{MS: n}, nil
+	default:
+		return Threshold{}, fmt.Errorf("threshold %q must end with %% or ms", raw)
+	}
+}
+
+// minPercentDeltaMS keeps percentage thresholds from flagging jitter on
+// very fast tests (1ms -> 2ms is +100%).
+const minPercentDeltaMS = 5
+
+func (t Threshold) exceeded(before float64, after float64) bool {
+	delta := after - before
+	if t.MS > 0 {
+		return delta > t.MS
+	}
+	if before <= 0 || delta < minPercentDeltaMS {
+		return false
+	}
+	return delta/before*100 > t.Percent
+}
+
+func (t Threshold) String() string {
+	if t.MS > 0 {
+		return strconv.FormatFloat(t.MS, 'f', -1, 64) + "ms"
+	}
+	return strconv.FormatFloat(t.Percent, 'f', -1, 64) + "%"
+}
+
+type TestChange struct {
+	File     string
+	Test     string
+	Before   model.TestStatus
+	After    model.TestStatus
+	BeforeMS int64
+	AfterMS  int64
+	Why      string
+}
+
+type MetricChange struct {
+	Metric     string
+	Before     float64
+	After      float64
+	Regression bool
+}
+
+type DiffResult struct {
+	Threshold      Threshold
+	NewlyFailing   []TestChange
+	NewlyPassing   []TestChange
+	Added          []TestChange
+	Removed        []TestChange
+	Slower         []TestChange
+	Faster         []TestChange
+	Load           []MetricChange
+	LoadRegression bool
+}
+
+func ReadJSON(path string) (model.RunReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return model.RunReport{}, fmt.Errorf("read report %s: %w", path, err)
+	}
+	var data model.RunReport
+	if err := json.Unmarshal(content, &data); err != nil {
+		return model.RunReport{}, fmt.Errorf("parse report %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func Diff(baseline model.RunReport, current model.RunReport, threshold Threshold) DiffResult {
+	out := DiffResult{Threshold: threshold}
+	before := indexTests(baseline)
+	after := indexTests(current)
+
+	for _, key := range sortedTestKeys(after) {
+		cur := after[key]
+		prev, ok := before[key]
+		change := TestChange{
+			File:    cur.file,
+			Test:    cur.test.Name,
+			After:   cur.test.Status,
+			AfterMS: cur.test.DurationMS,
+			Why:     cur.test.Message,
+		}
+		if !ok {
+			out.Added = append(out.Added, change)
+			continue
+		}
+		change.Before = prev.test.Status
+		change.BeforeMS = prev.test.DurationMS
+
+		switch {
+		case isFailing(cur.test.Status) && !isFailing(prev.test.Status):
+			out.NewlyFailing = append(out.NewlyFailing, change)
+		case cur.test.Status == model.StatusPass && isFailing(prev.test.Status):
+			out.NewlyPassing = append(out.NewlyPassing, change)
+		}
+		if prev.test.Status == model.StatusPass && cur.test.Status == model.StatusPass {
+			if threshold.exceeded(float64(prev.test.DurationMS), float64(cur.test.DurationMS)) {
+				out.Slower = append(out.Slower, change)
+			} else if threshold.exceeded(float64(cur.test.DurationMS), float64(prev.test.DurationMS)) {
+				out.Faster = append(out.Faster, change)
+			}
+		}
+	}
+	for _, key := range sortedTestKeys(before) {
+		if _, ok := after[key]; ok {
+			continue
+		}
+		prev := before[key]
+		out.Removed = append(out.Removed, TestChange{
+			File:     prev.file,
+			Test:     prev.test.Name,
+			Before:   prev.test.Status,
+			BeforeMS: prev.test.DurationMS,
+		})
+	}
+
+	if baseline.Load != nil && current.Load != nil {
+		out.Load = []MetricChange{
+			{Metric: "p95_ms", Before: baseline.Load.P95MS, After: current.Load.P95MS},
+			{Metric: "avg_ms", Before: baseline.Load.AvgMS, After: current.Load.AvgMS},
+			{Metric: "error_rate_%", Before: errorRate(baseline.Load), After: errorRate(current.Load)},
+		}
+		for i := range out.Load {
+			metric := &out.Load[i]
+			if metric.Metric == "error_rate_%" {
+				// Any growth in the error rate counts; the latency threshold does not apply.
+				metric.Regression = metric.After > metric.Before
+			} else {
+				metric.Regression = threshold.exceeded(metric.Before, metric.After)
+			}
+			if metric.Regression {
+				out.LoadRegression = true
+			}
+		}
+	}
+	return out
+}
+
+func (d DiffResult) HasRegressions() bool {
+	return len(d.NewlyFailing) > 0 || len(d.Slower) > 0 || d.LoadRegression
+}
+
+// Text renders the diff as a plain console table.
+func (d DiffResult) Text() string {
+	var b strings.Builder
+	writeSection := func(title string, changes []TestChange, row func(TestChange) string) {
+		if len(changes) == 0 {
+			return
+		}
+		b.WriteString(fmt.Sprintf("\n%s (%d)\n", title, len(changes)))
+		for _, change := range changes {
+			b.WriteString("  " + row(change) + "\n")
+		}
+	}
+	b.WriteString(d.summaryLine() + "\n")
+	writeSection("Newly failing", d.NewlyFailing, func(c TestChange) string {
+		return fmt.Sprintf("%s :: %s (%s -> %s) %s", c.File, c.Test, c.Before, c.After, c.Why)
+	})
+	writeSection("Newly passing", d.NewlyPassing, func(c TestChange) string {
+		return fmt.Sprintf("%s :: %s (%s -> %s)", c.File, c.Test, c.Before, c.After)
+	})
+	writeSection("Slower than "+d.Threshold.String(), d.Slower, latencyRow)
+	writeSection("Faster than "+d.Threshold.String(), d.Faster, latencyRow)
+	writeSection("Added", d.Added, func(c TestChange) string {
+		return fmt.Sprintf("%s :: %s (%s)", c.File, c.Test, c.After)
+	})
+	writeSection("Removed", d.Removed, func(c TestChange) string {
+		return fmt.Sprintf("%s :: %s", c.File, c.Test)
+	})
+	if len(d.Load) > 0 {
+		b.WriteString("\nLoad\n")
+		for _, metric := range d.Load {
+			marker := ""
+			if metric.Regression {
+				marker = "  REGRESSION"
+			}
+			b.WriteString(fmt.Sprintf("  %-13s %10.2f -> %10.2f (%s)%s\n", metric.Metric, metric.Before, metric.After, signedDelta(metric.Before, metric.After), marker))
+		}
+	}
+	return b.String()
+}
+
+// Markdown renders the diff for a pull request comment.
+func (d DiffResult) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## ReqRes report diff\n\n")
+	b.WriteString(d.summaryLine() + "\n")
+	writeTable := func(title string, changes []TestChange, header string, row func(TestChange) string) {
+		if len(changes) == 0 {
+			return
+		}
+		b.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", title, len(changes)))
+		b.WriteString(header + "\n")
+		b.WriteString(strings.Repeat("|---", strings.Count(header, "|")-1) + "|\n")
+		for _, change := range changes {
+			b.WriteString(row(change) + "\n")
+		}
+	}
+	writeTable("Newly failing", d.NewlyFailing, "| File | Test | Before | After | Why |", func(c TestChange) string {
+		return fmt.Sprintf("| %s | %s | %s | %s | %s |", mdCell(c.File), mdCell(c.Test), c.Before, c.After, mdCell(c.Why))
+	})
+	writeTable("Newly passing", d.NewlyPassing, "| File | Test | Before | After |", func(c TestChange) string {
+		return fmt.Sprintf("| %s | %s | %s | %s |", mdCell(c.File), mdCell(c.Test), c.Before, c.After)
+	})
+	latencyHeader := "| File | Test | Before (ms) | After (ms) | Change |"
+	latencyMD := func(c TestChange) string {
+		return fmt.Sprintf("| %s | %s | %d | %d | %s |", mdCell(c.File), mdCell(c.Test), c.BeforeMS, c.AfterMS, signedDelta(float64(c.BeforeMS), float64(c.AfterMS)))
+	}
+	writeTable("Slower than "+d.Threshold.String(), d.Slower, latencyHeader, latencyMD)
+	writeTable("Faster than "+d.Threshold.String(), d.Faster, latencyHeader, latencyMD)
+	writeTable("Added", d.Added, "| File | Test | Status |", func(c TestChange) string {
+		return fmt.Sprintf("| %s | %s | %s |", mdCell(c.File), mdCell(c.Test), c.After)
+	})
+	writeTable("Removed", d.Removed, "| File | Test |", func(c TestChange) string {
+		return fmt.Sprintf("| %s | %s |", mdCell(c.File), mdCell(c.Test))
+	})
+	if len(d.Load) > 0 {
+		b.WriteString("\n### Load\n\n| Metric | Before | After | Change | |\n|---|---|---|---|---|\n")
+		for _, metric := range d.Load {
+			marker := ""
+			if metric.Regression {
+				marker = ":warning: regression"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %.2f | %.2f | %s | %s |\n", metric.Metric, metric.Before, metric.After, signedDelta(metric.Before, metric.After), marker))
+		}
+	}
+	return b.String()
+}
+
+func (d DiffResult) summaryLine() string {
+	return fmt.Sprintf("newly failing=%d newly passing=%d slower=%d faster=%d added=%d removed=%d",
+		len(d.NewlyFailing), len(d.NewlyPassing), len(d.Slower), len(d.Faster), len(d.Added), len(d.Removed))
+}
+
+type indexedTest struct {
+	file string
+	test model.TestResult
+}
+
+func indexTests(data model.RunReport) map[string]indexedTest {
+	out := map[string]indexedTest{}
+	for _, file := range data.Files {
+		for _, test := range file.Tests {
+			out[file.File+"::"+test.Name] = indexedTest{file: file.File, test: test}
+		}
+	}
+	return out
+}
+
+func sortedTestKeys(items map[string]indexedTest) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isFailing(status model.TestStatus) bool {
+	return status == model.StatusFail || status == model.StatusFlaky
+}
+
+func errorRate(load *model.LoadSummary) float64 {
+	if load.Requests == 0 {
+		return 0
+	}
+	return float64(load.Failures) / float64(load.Requests) * 100
+}
+
+func latencyRow(c TestChange) string {
+	return fmt.Sprintf("%s :: %s %dms -> %dms (%s)", c.File, c.Test, c.BeforeMS, c.AfterMS, signedDelta(float64(c.BeforeMS), float64(c.AfterMS)))
+}
+
+func signedDelta(before float64, after float64) string {
+	delta := after - before
+	if before == 0 {
+		return fmt.Sprintf("%+.2f", delta)
+	}
+	return fmt.Sprintf("%+.2f, %+.1f%%", delta, delta/before*100)
+}
+
+func mdCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/runner"
+)
+
+// GraphFile is one suite's dependency graph as `reqres list --graph` renders it.
+type GraphFile struct {
+	File  string
+	Graph runner.Graph
+}
+
+// Dot renders the dependency graphs as a Graphviz digraph with one cluster
+// per suite. Edges point from a dependency to the test waiting on it.
+func Dot(files []GraphFile) string {
+	var b strings.Builder
+	b.WriteString("digraph reqres {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for i, file := range files {
+		ids := graphIDs(i, file.Graph)
+		b.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+		b.WriteString(fmt.Sprintf("    label=%s;\n", dotQuote(file.File)))
+		for _, node := range file.Graph.Nodes {
+			attrs := "label=" + dotQuote(node.Test.Name)
+			if node.Wave < 0 {
+				attrs += ", color=red"
+			}
+			b.WriteString(fmt.Sprintf("    %s [%s];\n", ids[node.Test.Name], attrs))
+		}
+		for _, dep := range unselectedDeps(file.Graph) {
+			b.WriteString(fmt.Sprintf("    %s [label=%s, style=dashed];\n", ids[dep], dotQuote(dep+" (not selected)")))
+		}
+		for _, node := range file.Graph.Nodes {
+			if node.Test.After == "" {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("    %s -> %s;\n", ids[node.Test.After], ids[node.Test.Name]))
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the dependency graphs as a Mermaid flowchart with one
+// subgraph per suite, ready to paste into Markdown docs.
+func Mermaid(files []GraphFile) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, file := range files {
+		ids := graphIDs(i, file.Graph)
+		b.WriteString(fmt.Sprintf("  subgraph f%d[%s]\n", i, mermaidQuote(file.File)))
+		for _, node := range file.Graph.Nodes {
+			b.WriteString(fmt.Sprintf("    %s[%s]\n", ids[node.Test.Name], mermaidQuote(node.Test.Name)))
+		}
+		for _, dep := range unselectedDeps(file.Graph) {
+			b.WriteString(fmt.Sprintf("    %s[%s]\n", ids[dep], mermaidQuote(dep+" (not selected)")))
+			b.WriteString(fmt.Sprintf("    style %s stroke-dasharray: 5 5\n", ids[dep]))
+		}
+		for _, node := range file.Graph.Nodes {
+			if node.Test.After == "" {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("    %s --> %s\n", ids[node.Test.After], ids[node.Test.Name]))
+		}
+		for _, node := range file.Graph.Cyclic() {
+			b.WriteString(fmt.Sprintf("    style %s stroke:red\n", ids[node.Test.Name]))
+		}
+		b.WriteString("  end\n")
+	}
+	return b.String()
+}
+
+// graphIDs assigns stable node ids, since test names may contain anything.
+func graphIDs(file int, graph runner.Graph) map[string]string {
+	ids := map[string]string{}
+	for i, node := range graph.Nodes {
+		ids[node.Test.Name] = fmt.Sprintf("f%d_t%d", file, i)
+	}
+	for i, dep := range unselectedDeps(graph) {
+		ids[dep] = fmt.Sprintf("f%d_x%d", file, i)
+	}
+	return ids
+}
+
+func unselectedDeps(graph runner.Graph) []string {
+	var deps []string
+	seen := map[string]bool{}
+	for _, node := range graph.Nodes {
+		if node.Unselected && !seen[node.Test.After] {
+			seen[node.Test.After] = true
+			deps = append(deps, node.Test.After)
+		}
+	}
+	return deps
+}
+
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func mermaidQuote(value string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(value) + `"`
+}
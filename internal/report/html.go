@@ -0,0 +1,226 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
+)
+
+// WriteHTML writes a single self-contained report file. Styles, scripts and
+// charts are inlined so the file works offline as a CI artifact.
+func WriteHTML(path string, data model.RunReport) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data = secrets.RedactReport(data)
+
+	var b strings.Builder
+	b.WriteString("<!doctype html><html><head><meta charset=\"utf-8\">")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">")
+	b.WriteString("<title>ReqRes Report</title>")
+	b.WriteString("<style>")
+	b.WriteString(htmlStyle)
+	b.WriteString("</style></head><body>")
+	b.WriteString("<h1>ReqRes Run Report</h1>")
+	b.WriteString("<div class=\"card\">")
+	b.WriteString(fmt.Sprintf("<p><strong>Total:</strong> %d | <strong>Pass:</strong> %d | <strong>Fail:</strong> %d | <strong>Skip:</strong> %d</p>",
+		data.Total, data.Passed, data.Failed, data.Skipped))
+	b.WriteString(fmt.Sprintf("<p><strong>Started:</strong> %s | <strong>Duration:</strong> %d ms</p>",
+		html.EscapeString(data.StartedAt.Format("2006-01-02 15:04:05 MST")), data.DurationMS))
+	if len(data.Flaky) > 0 {
+		b.WriteString("<p><strong>Flaky:</strong> " + html.EscapeString(strings.Join(data.Flaky, ", ")) + "</p>")
+	}
+	b.WriteString("</div>")
+
+	writeFilters(&b, data)
+
+	b.WriteString("<div class=\"card\"><h2>Timeline</h2>")
+	b.WriteString(timelineSVG(data))
+	b.WriteString("</div>")
+
+	for _, file := range data.Files {
+		b.WriteString("<div class=\"card file\" data-file=\"" + html.EscapeString(file.File) + "\">")
+		b.WriteString(fmt.Sprintf("<h2>%s <span class=\"muted\">%d ms</span></h2>", html.EscapeString(file.File), file.Duration))
+		b.WriteString("<table><thead><tr><th>Test</th><th>Method</th><th>Path</th><th>Status</th><th>Message</th><th>Duration (ms)</th></tr></thead><tbody>")
+		for _, test := range file.Tests {
+			b.WriteString("<tr class=\"item\"" + itemAttrs(file.File, test) + ">")
+			b.WriteString("<td>" + html.EscapeString(test.Name) + tagBadges(test.Tags) + "</td>")
+			b.WriteString("<td>" + html.EscapeString(test.Method) + "</td>")
+			b.WriteString("<td class=\"mono\">" + html.EscapeString(test.Path) + "</td>")
+			b.WriteString(fmt.Sprintf("<td class=\"%s\">%s</td>", string(test.Status), html.EscapeString(string(test.Status))))
+			b.WriteString("<td>" + html.EscapeString(test.Message) + testDetails(test) + "</td>")
+			b.WriteString(fmt.Sprintf("<td>%d</td>", test.DurationMS))
+			b.WriteString("</tr>")
+		}
+		b.WriteString("</tbody></table>")
+		b.WriteString("</div>")
+	}
+
+	if data.Load != nil {
+		load := data.Load
+		b.WriteString("<div class=\"card\">")
+		b.WriteString("<h2>Load Test</h2>")
+		b.WriteString(fmt.Sprintf("<p>%s %s | users=%d | requests=%d | success=%d | fail=%d</p>",
+			html.EscapeString(load.Method), html.EscapeString(load.Path), load.Users, load.Requests, load.Successes, load.Failures))
+		b.WriteString(fmt.Sprintf("<p>avg=%0.2fms p95=%0.2fms min=%0.2fms max=%0.2fms</p>", load.AvgMS, load.P95MS, load.MinMS, load.MaxMS))
+		b.WriteString(loadChartSVG(load.Timeline))
+		b.WriteString("</div>")
+	}
+
+	b.WriteString("<script>")
+	b.WriteString(htmlScript)
+	b.WriteString("</script>")
+	b.WriteString("</body></html>")
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+func writeFilters(b *strings.Builder, data model.RunReport) {
+	tags := map[string]struct{}{}
+	for _, file := range data.Files {
+		for _, test := range file.Tests {
+			for _, tag := range test.Tags {
+				tags[tag] = struct{}{}
+			}
+		}
+	}
+	tagList := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagList = append(tagList, tag)
+	}
+	sort.Strings(tagList)
+
+	b.WriteString("<div class=\"card filters\">")
+	b.WriteString("<input id=\"f-search\" type=\"search\" placeholder=\"Search test name\">")
+	b.WriteString("<select id=\"f-status\"><option value=\"\">All statuses</option>")
+	for _, status := range []model.TestStatus{model.StatusPass, model.StatusFail, model.StatusSkip, model.StatusFlaky, model.StatusCancelled} {
+		b.WriteString(fmt.Sprintf("<option value=\"%s\">%s</option>", status, status))
+	}
+	b.WriteString("</select>")
+	b.WriteString("<select id=\"f-tag\"><option value=\"\">All tags</option>")
+	for _, tag := range tagList {
+		b.WriteString("<option>" + html.EscapeString(tag) + "</option>")
+	}
+	b.WriteString("</select>")
+	b.WriteString("<select id=\"f-file\"><option value=\"\">All files</option>")
+	for _, file := range data.Files {
+		b.WriteString("<option>" + html.EscapeString(file.File) + "</option>")
+	}
+	b.WriteString("</select>")
+	b.WriteString("<span id=\"f-count\" class=\"muted\"></span>")
+	b.WriteString("</div>")
+}
+
+func itemAttrs(file string, test model.TestResult) string {
+	return fmt.Sprintf(" data-status=\"%s\" data-tags=\"%s\" data-file=\"%s\" data-name=\"%s\"",
+		html.EscapeString(string(test.Status)),
+		html.EscapeString(" "+strings.Join(test.Tags, " ")+" "),
+		html.EscapeString(file),
+		html.EscapeString(strings.ToLower(test.Name)))
+}
+
+func tagBadges(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, tag := range tags {
+		b.WriteString(" <span class=\"tag\">" + html.EscapeString(tag) + "</span>")
+	}
+	return b.String()
+}
+
+func testDetails(test model.TestResult) string {
+	if test.Request == nil && test.Response == nil && len(test.Captures) == 0 && test.After == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<details><summary>Details</summary>")
+	if test.After != "" {
+		b.WriteString("<p><strong>After:</strong> " + html.EscapeString(test.After) + "</p>")
+	}
+	if test.Attempts > 1 {
+		b.WriteString(fmt.Sprintf("<p><strong>Attempts:</strong> %d</p>", test.Attempts))
+	}
+	if req := test.Request; req != nil {
+		b.WriteString("<h4>Request</h4><pre>")
+		b.WriteString(html.EscapeString(req.Method + " " + req.URL + "\n"))
+		writeHeaderLines(&b, req.Headers)
+		if req.Body != "" {
+			b.WriteString("\n" + html.EscapeString(req.Body))
+		}
+		b.WriteString("</pre>")
+	}
+	if resp := test.Response; resp != nil {
+		b.WriteString("<h4>Response</h4><pre>")
+		b.WriteString(fmt.Sprintf("HTTP %d\n", resp.StatusCode))
+		writeHeaderLines(&b, resp.Headers)
+		if resp.Body != "" {
+			b.WriteString("\n" + html.EscapeString(resp.Body))
+		}
+		if resp.Truncated {
+			b.WriteString("\n… (truncated)")
+		}
+		b.WriteString("</pre>")
+	}
+	if len(test.Captures) > 0 {
+		b.WriteString("<h4>Captures</h4><pre>")
+		keys := sortedKeys(test.Captures)
+		for _, key := range keys {
+			b.WriteString(html.EscapeString(key + " = " + test.Captures[key] + "\n"))
+		}
+		b.WriteString("</pre>")
+	}
+	b.WriteString("</details>")
+	return b.String()
+}
+
+func writeHeaderLines(b *strings.Builder, headers map[string]string) {
+	for _, key := range sortedKeys(headers) {
+		b.WriteString(html.EscapeString(key + ": " + headers[key] + "\n"))
+	}
+}
+
+func sortedKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const htmlStyle = `body{font-family:Segoe UI,Arial,sans-serif;background:#f5f7fb;color:#172033;padding:20px;}
+.card{background:#fff;border-radius:12px;padding:16px;margin-bottom:16px;box-shadow:0 8px 24px rgba(20,30,60,.08);overflow-x:auto;}
+table{width:100%;border-collapse:collapse;}th,td{padding:8px;border-bottom:1px solid #e5e7ef;text-align:left;vertical-align:top;}
+.pass{color:#0a7b35;font-weight:600}.fail{color:#a40f2c;font-weight:600}.skip{color:#8a6c00;font-weight:600}.flaky{color:#b45309;font-weight:600}.cancelled{color:#6b7280;font-weight:600}
+.muted{color:#6b7280;font-size:.85em;font-weight:400}.mono{font-family:Consolas,monospace;font-size:.9em}
+.tag{display:inline-block;background:#eef2ff;color:#3730a3;border-radius:8px;padding:0 6px;font-size:.75em;margin-left:2px}
+.filters{display:flex;gap:8px;flex-wrap:wrap;align-items:center}.filters input,.filters select{padding:6px;border:1px solid #d1d5db;border-radius:6px}
+details summary{cursor:pointer;color:#2563eb}pre{background:#f3f4f6;padding:8px;border-radius:6px;white-space:pre-wrap;word-break:break-all;max-height:320px;overflow:auto}
+svg text{font-size:11px;fill:#374151}.bar-pass{fill:#22c55e}.bar-fail{fill:#ef4444}.bar-skip{fill:#eab308}.bar-flaky{fill:#f97316}.bar-cancelled{fill:#9ca3af}
+.dep{stroke:#6366f1;stroke-width:1.2;fill:none}.grid{stroke:#e5e7ef}.hidden{display:none}`
+
+const htmlScript = `(function(){
+var q=function(id){return document.getElementById(id);};
+function apply(){
+  var text=q('f-search').value.toLowerCase(),status=q('f-status').value,tag=q('f-tag').value,file=q('f-file').value,shown=0,total=0;
+  document.querySelectorAll('.item').forEach(function(el){
+    var ok=(!text||el.dataset.name.indexOf(text)>=0)&&(!status||el.dataset.status===status)&&(!tag||el.dataset.tags.indexOf(' '+tag+' ')>=0)&&(!file||el.dataset.file===file);
+    el.classList.toggle('hidden',!ok);
+    if(el.tagName==='TR'){total++;if(ok){shown++;}}
+  });
+  document.querySelectorAll('.file').forEach(function(el){el.classList.toggle('hidden',!!file&&el.dataset.file!==file);});
+  q('f-count').textContent=shown+' of '+total+' tests';
+}
+['f-search','f-status','f-tag','f-file'].forEach(function(id){q(id).addEventListener('input',apply);});
+apply();
+})();`
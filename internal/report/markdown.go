@@ -0,0 +1,85 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
+)
+
+// Markdown renders a run summary for GitHub job summaries and PR comments.
+func Markdown(data model.RunReport) string {
+	data = secrets.RedactReport(data)
+	var b strings.Builder
+
+	icon := ":white_check_mark:"
+	switch {
+	case data.Failed > 0:
+		icon = ":x:"
+	case data.Stopped != "" || data.Cancelled > 0:
+		icon = ":warning:"
+	}
+	b.WriteString(fmt.Sprintf("## %s ReqRes results\n\n", icon))
+	b.WriteString(fmt.Sprintf("**%d** tests: **%d** passed, **%d** failed, **%d** skipped, **%d** cancelled in %d ms\n\n",
+		data.Total, data.Passed, data.Failed, data.Skipped, data.Cancelled, data.DurationMS))
+	if data.Stopped != "" {
+		b.WriteString(fmt.Sprintf("> **Stopped early:** %s\n\n", mdCell(data.Stopped)))
+	}
+
+	b.WriteString("| File | Total | Pass | Fail | Skip | Cancelled | Duration (ms) |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, file := range data.Files {
+		b.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d | %d |\n",
+			mdCell(file.File), file.Total, file.Passed, file.Failed, file.Skipped, file.Cancelled, file.Duration))
+	}
+
+	if len(data.Failures) > 0 {
+		b.WriteString(fmt.Sprintf("\n### Failures (%d)\n\n", len(data.Failures)))
+		b.WriteString("| File | Test | Reason |\n|---|---|---|\n")
+		for _, failure := range data.Failures {
+			b.WriteString(fmt.Sprintf("| %s | %s | %s |\n", mdCell(failure.File), mdCell(failure.Test), mdCode(failure.Why)))
+		}
+	}
+
+	if len(data.Flaky) > 0 {
+		b.WriteString(fmt.Sprintf("\n### Flaky (%d)\n\n", len(data.Flaky)))
+		for _, name := range data.Flaky {
+			b.WriteString("- " + mdCell(name) + "\n")
+		}
+	}
+
+	if load := data.Load; load != nil {
+		b.WriteString("\n### Load test\n\n")
+		stopped := ""
+		if load.Cancelled {
+			stopped = " (stopped early)"
+		}
+		b.WriteString(fmt.Sprintf("`%s %s` with %d users for %d ms%s\n\n", load.Method, load.Path, load.Users, load.DurationMS, stopped))
+		b.WriteString("| Requests | Success | Fail | Avg (ms) | p95 (ms) | Min (ms) | Max (ms) |\n")
+		b.WriteString("|---:|---:|---:|---:|---:|---:|---:|\n")
+		b.WriteString(fmt.Sprintf("| %d | %d | %d | %.2f | %.2f | %.2f | %.2f |\n",
+			load.Requests, load.Successes, load.Failures, load.AvgMS, load.P95MS, load.MinMS, load.MaxMS))
+	}
+	return b.String()
+}
+
+func WriteMarkdown(path string, data model.RunReport) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(Markdown(data)), 0o644)
+}
+
+func mdCode(value string) string {
+	value = mdCell(value)
+	if value == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(value, "`", "'") + "`"
+}
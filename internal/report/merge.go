@@ -0,0 +1,122 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/DevrajJain04/reqres/internal/config"
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+// Tally recomputes the per-file and run totals and the failure list from
+// the test results.
+func Tally(data model.RunReport) model.RunReport {
+	data.Total, data.Passed, data.Failed, data.Skipped, data.Cancelled = 0, 0, 0, 0, 0
+	data.Failures = nil
+	for i := range data.Files {
+		file := &data.Files[i]
+		file.Total, file.Passed, file.Failed, file.Skipped, file.Cancelled = len(file.Tests), 0, 0, 0, 0
+		for _, test := range file.Tests {
+			switch test.Status {
+			case model.StatusPass:
+				file.Passed++
+			case model.StatusFail, model.StatusFlaky:
+				file.Failed++
+				data.Failures = append(data.Failures, model.FailureEntry{
+					File:   file.File,
+					Source: test.Source,
+					Line:   test.Line,
+					Test:   test.Name,
+					Why:    test.Message,
+				})
+			case model.StatusSkip:
+				file.Skipped++
+			case model.StatusCancelled:
+				file.Cancelled++
+			}
+		}
+		data.Total += file.Total
+		data.Passed += file.Passed
+		data.Failed += file.Failed
+		data.Skipped += file.Skipped
+		data.Cancelled += file.Cancelled
+	}
+	return data
+}
+
+// FailedTests lists the failed tests of a report by suite label, in the
+// shape of model.RunOptions.Only. Labels are normalized with
+// config.CleanLabel.
+func FailedTests(data model.RunReport) map[string][]string {
+	out := map[string][]string{}
+	for _, failure := range data.Failures {
+		label := config.CleanLabel(failure.File)
+		out[label] = append(out[label], failure.Test)
+	}
+	return out
+}
+
+// Merge folds a rerun into the report it was started from: every test the
+// rerun ran replaces the original result, everything else is kept. Suites
+// match by their normalized label, so `./a.yaml` and `a.yaml` are one suite.
+func Merge(base model.RunReport, rerun model.RunReport) model.RunReport {
+	rerunTests := map[string]model.TestResult{}
+	rerunFiles := map[string]int64{}
+	for _, file := range rerun.Files {
+		label := config.CleanLabel(file.File)
+		rerunFiles[label] += file.Duration
+		for _, test := range file.Tests {
+			rerunTests[testKey(label, test.Name)] = test
+		}
+	}
+
+	out := base
+	out.Files = make([]model.FileReport, 0, len(base.Files))
+	seenFiles := map[string]bool{}
+	for _, file := range base.Files {
+		label := config.CleanLabel(file.File)
+		seenFiles[label] = true
+		merged := file
+		merged.Tests = make([]model.TestResult, 0, len(file.Tests))
+		for _, test := range file.Tests {
+			if replacement, ok := rerunTests[testKey(label, test.Name)]; ok {
+				test = replacement
+			}
+			merged.Tests = append(merged.Tests, test)
+		}
+		merged.Duration += rerunFiles[label]
+		out.Files = append(out.Files, merged)
+	}
+	for _, file := range rerun.Files {
+		if !seenFiles[config.CleanLabel(file.File)] {
+			out.Files = append(out.Files, file)
+		}
+	}
+
+	flaky := []string{}
+	for _, key := range base.Flaky {
+		if _, rerunRan := rerunTests[key]; !rerunRan {
+			flaky = append(flaky, key)
+		}
+	}
+	flaky = append(flaky, rerun.Flaky...)
+	sort.Strings(flaky)
+	out.Flaky = flaky
+	if len(out.Flaky) == 0 {
+		out.Flaky = nil
+	}
+
+	if rerun.FinishedAt.After(out.FinishedAt) {
+		out.FinishedAt = rerun.FinishedAt
+	}
+	out.DurationMS += rerun.DurationMS
+	out.SnapshotsSaved += rerun.SnapshotsSaved
+	if rerun.Load != nil {
+		out.Load = rerun.Load
+	}
+	return Tally(out)
+}
+
+// testKey matches the `file::test` keys used in RunReport.Flaky.
+func testKey(file string, test string) string {
+	return file + "::" + test
+}
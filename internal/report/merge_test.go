@@ -0,0 +1,114 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+func result(name string, status model.TestStatus) model.TestResult {
+	return model.TestResult{Name: name, Status: status, Message: string(status)}
+}
+
+func TestTally(t *testing.T) {
+	data := Tally(model.RunReport{
+		Total: 99,
+		Files: []model.FileReport{
+			{File: "a.yaml", Tests: []model.TestResult{
+				result("pass", model.StatusPass),
+				result("fail", model.StatusFail),
+				result("flaky", model.StatusFlaky),
+			}},
+			{File: "b.yaml", Tests: []model.TestResult{
+				result("skip", model.StatusSkip),
+				result("cancelled", model.StatusCancelled),
+			}},
+		},
+		Failures: []model.FailureEntry{{File: "stale.yaml", Test: "old"}},
+	})
+
+	if data.Total != 5 || data.Passed != 1 || data.Failed != 2 || data.Skipped != 1 || data.Cancelled != 1 {
+		t.Fatalf("run totals: %+v", data)
+	}
+	a, b := data.Files[0], data.Files[1]
+	if a.Total != 3 || a.Passed != 1 || a.Failed != 2 {
+		t.Fatalf("a.yaml totals: %+v", a)
+	}
+	if b.Total != 2 || b.Skipped != 1 || b.Cancelled != 1 {
+		t.Fatalf("b.yaml totals: %+v", b)
+	}
+	want := []model.FailureEntry{
+		{File: "a.yaml", Test: "fail", Why: "fail"},
+		{File: "a.yaml", Test: "flaky", Why: "flaky"},
+	}
+	if !reflect.DeepEqual(data.Failures, want) {
+		t.Fatalf("failures = %+v, want %+v", data.Failures, want)
+	}
+}
+
+func TestFailedTests(t *testing.T) {
+	data := model.RunReport{Failures: []model.FailureEntry{
+		{File: "./a.yaml", Test: "one"},
+		{File: "a.yaml", Test: "two"},
+		{File: "dir/../b.yaml#2", Test: "three"},
+	}}
+	want := map[string][]string{
+		"a.yaml":   {"one", "two"},
+		"b.yaml#2": {"three"},
+	}
+	if got := FailedTests(data); !reflect.DeepEqual(got, want) {
+		t.Fatalf("FailedTests = %v, want %v", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := Tally(model.RunReport{
+		DurationMS: 100,
+		Flaky:      []string{"a.yaml::flaky", "b.yaml::kept"},
+		Files: []model.FileReport{
+			{File: "a.yaml", Duration: 40, Tests: []model.TestResult{
+				result("ok", model.StatusPass),
+				result("broken", model.StatusFail),
+				result("flaky", model.StatusFlaky),
+			}},
+			{File: "b.yaml", Duration: 60, Tests: []model.TestResult{
+				result("kept", model.StatusFail),
+			}},
+		},
+	})
+	rerun := model.RunReport{
+		DurationMS: 10,
+		Files: []model.FileReport{
+			{File: "./a.yaml", Duration: 10, Tests: []model.TestResult{
+				result("broken", model.StatusPass),
+				result("flaky", model.StatusPass),
+			}},
+			{File: "c.yaml", Duration: 5, Tests: []model.TestResult{
+				result("new", model.StatusPass),
+			}},
+		},
+	}
+
+	merged := Merge(base, rerun)
+
+	if len(merged.Files) != 3 {
+		t.Fatalf("files = %+v, want a.yaml, b.yaml and c.yaml", merged.Files)
+	}
+	a := merged.Files[0]
+	if a.File != "a.yaml" || a.Passed != 3 || a.Failed != 0 || a.Duration != 50 {
+		t.Fatalf("a.yaml = %+v", a)
+	}
+	if merged.Files[1].Failed != 1 || merged.Files[2].File != "c.yaml" {
+		t.Fatalf("files = %+v", merged.Files)
+	}
+	if merged.Total != 5 || merged.Passed != 4 || merged.Failed != 1 || merged.DurationMS != 110 {
+		t.Fatalf("totals = %+v", merged)
+	}
+	if want := []string{"b.yaml::kept"}; !reflect.DeepEqual(merged.Flaky, want) {
+		t.Fatalf("flaky = %v, want %v", merged.Flaky, want)
+	}
+	if len(merged.Failures) != 1 || merged.Failures[0].Test != "kept" {
+		t.Fatalf("failures = %+v", merged.Failures)
+	}
+}
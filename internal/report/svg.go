@@ -0,0 +1,208 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+const (
+	timelineLabelWidth = 240.0
+	timelineChartWidth = 760.0
+	timelineRowHeight  = 20.0
+	timelineAxisHeight = 24.0
+)
+
+// timelineSVG draws a Gantt chart of every executed test. Bars that overlap
+// ran in parallel; connectors link each test to the `after` test it waited on.
+func timelineSVG(data model.RunReport) string {
+	type row struct {
+		file   string
+		test   model.TestResult
+		header bool
+	}
+	rows := []row{}
+	start := data.StartedAt
+	end := data.StartedAt
+	for _, file := range data.Files {
+		if len(data.Files) > 1 {
+			rows = append(rows, row{file: file.File, header: true})
+		}
+		for _, test := range file.Tests {
+			if test.StartedAt.IsZero() {
+				continue
+			}
+			if start.IsZero() || test.StartedAt.Before(start) {
+				start = test.StartedAt
+			}
+			testEnd := test.StartedAt.Add(time.Duration(test.DurationMS) * time.Millisecond)
+			if testEnd.After(end) {
+				end = testEnd
+			}
+			rows = append(rows, row{file: file.File, test: test})
+		}
+	}
+	if len(rows) == 0 {
+		return "<p class=\"muted\">No executed tests.</p>"
+	}
+	spanMS := float64(end.Sub(start).Milliseconds())
+	if spanMS < 1 {
+		spanMS = 1
+	}
+	scale := func(ms float64) float64 {
+		return timelineLabelWidth + ms/spanMS*timelineChartWidth
+	}
+
+	height := timelineAxisHeight + float64(len(rows))*timelineRowHeight + 8
+	width := timelineLabelWidth + timelineChartWidth + 16
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<svg viewBox=\"0 0 %.0f %.0f\" width=\"100%%\" role=\"img\" aria-label=\"Test timeline\">", width, height))
+
+	for i := 0; i <= 4; i++ {
+		ms := spanMS * float64(i) / 4
+		x := scale(ms)
+		b.WriteString(fmt.Sprintf("<line class=\"grid\" x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\"/>", x, timelineAxisHeight-6, x, height))
+		b.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"12\" text-anchor=\"middle\">%.0f ms</text>", x, ms))
+	}
+
+	type barPos struct {
+		x, w, y float64
+	}
+	// Place every bar first so a connector can point at a test listed
+	// before the one it runs after.
+	bars := map[string]barPos{}
+	for i, item := range rows {
+		if item.header {
+			continue
+		}
+		test := item.test
+		w := float64(test.DurationMS) / spanMS * timelineChartWidth
+		if w < 2 {
+			w = 2
+		}
+		bars[item.file+"\x00"+test.Name] = barPos{
+			x: scale(float64(test.StartedAt.Sub(start).Milliseconds())),
+			w: w,
+			y: timelineAxisHeight + float64(i)*timelineRowHeight,
+		}
+	}
+
+	var deps strings.Builder
+	for i, item := range rows {
+		if item.header {
+			y := timelineAxisHeight + float64(i)*timelineRowHeight
+			b.WriteString(fmt.Sprintf("<text x=\"4\" y=\"%.1f\" font-weight=\"600\">%s</text>", y+14, html.EscapeString(truncateLabel(item.file, 40))))
+			continue
+		}
+		test := item.test
+		bar := bars[item.file+"\x00"+test.Name]
+		x, w, y := bar.x, bar.w, bar.y
+		midY := y + timelineRowHeight/2
+		if test.After != "" {
+			if dep, ok := bars[item.file+"\x00"+test.After]; ok {
+				depEnd, depMid := dep.x+dep.w, dep.y+timelineRowHeight/2
+				deps.WriteString(fmt.Sprintf("<path class=\"dep\" d=\"M%.1f %.1f H%.1f V%.1f H%.1f\"/>",
+					depEnd, depMid, (depEnd+x)/2, midY, x))
+			}
+		}
+		b.WriteString("<g class=\"item\"" + itemAttrs(item.file, test) + ">")
+		b.WriteString(fmt.Sprintf("<text x=\"12\" y=\"%.1f\">%s</text>", y+14, html.EscapeString(truncateLabel(test.Name, 36))))
+		b.WriteString(fmt.Sprintf("<rect class=\"bar-%s\" x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" rx=\"3\">",
+			string(test.Status), x, y+3, w, timelineRowHeight-6))
+		b.WriteString(fmt.Sprintf("<title>%s: %s, %d ms</title></rect>", html.EscapeString(test.Name), test.Status, test.DurationMS))
+		b.WriteString("</g>")
+	}
+	b.WriteString(deps.String())
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// loadChartSVG plots p50/p95/p99 latency for each second of a load run.
+func loadChartSVG(points []model.LoadSample) string {
+	if len(points) == 0 {
+		return ""
+	}
+	const (
+		width   = 800.0
+		height  = 240.0
+		left    = 56.0
+		right   = 16.0
+		top     = 16.0
+		bottom  = 32.0
+		plotW   = width - left - right
+		plotH   = height - top - bottom
+		maxTick = 4
+	)
+	maxMS := 1.0
+	for _, point := range points {
+		if point.P99MS > maxMS {
+			maxMS = point.P99MS
+		}
+	}
+	lastSecond := float64(points[len(points)-1].Second)
+	if lastSecond < 1 {
+		lastSecond = 1
+	}
+	xAt := func(second int) float64 {
+		return left + float64(second)/lastSecond*plotW
+	}
+	yAt := func(ms float64) float64 {
+		return top + plotH - ms/maxMS*plotH
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<svg viewBox=\"0 0 %.0f %.0f\" width=\"100%%\" role=\"img\" aria-label=\"Load latency percentiles\">", width, height))
+	for i := 0; i <= maxTick; i++ {
+		ms := maxMS * float64(i) / maxTick
+		y := yAt(ms)
+		b.WriteString(fmt.Sprintf("<line class=\"grid\" x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\"/>", left, y, width-right, y))
+		b.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%.0f ms</text>", left-6, y+4, ms))
+	}
+	for i := 0; i <= maxTick; i++ {
+		second := int(lastSecond * float64(i) / maxTick)
+		b.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">%ds</text>", xAt(second), height-10, second))
+	}
+
+	series := []struct {
+		label string
+		color string
+		value func(model.LoadSample) float64
+	}{
+		{"p50", "#22c55e", func(p model.LoadSample) float64 { return p.P50MS }},
+		{"p95", "#f97316", func(p model.LoadSample) float64 { return p.P95MS }},
+		{"p99", "#ef4444", func(p model.LoadSample) float64 { return p.P99MS }},
+	}
+	for i, s := range series {
+		coords := make([]string, 0, len(points))
+		for _, point := range points {
+			if point.Requests == 0 {
+				continue
+			}
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", xAt(point.Second), yAt(s.value(point))))
+		}
+		b.WriteString(fmt.Sprintf("<polyline fill=\"none\" stroke=\"%s\" stroke-width=\"2\" points=\"%s\"/>", s.color, strings.Join(coords, " ")))
+		lx := left + 12 + float64(i)*72
+		b.WriteString(fmt.Sprintf("<rect x=\"%.1f\" y=\"%.1f\" width=\"12\" height=\"4\" fill=\"%s\"/>", lx, top, s.color))
+		b.WriteString(fmt.Sprintf("<text x=\"%.1f\" y=\"%.1f\">%s</text>", lx+16, top+5, s.label))
+	}
+	for _, point := range points {
+		if point.Failures == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("<circle cx=\"%.1f\" cy=\"%.1f\" r=\"3\" fill=\"#a40f2c\"><title>%ds: %d of %d requests failed</title></circle>",
+			xAt(point.Second), top+plotH, point.Second, point.Failures, point.Requests))
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+func truncateLabel(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/httpx"
+	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
+	"github.com/DevrajJain04/reqres/internal/utils"
+)
+
+// PlannedRequest is what RunFile would send for one test.
+type PlannedRequest struct {
+	Test    model.TestCase
+	Wave    int
+	Request httpx.Prepared
+	// Skip says why the test would not be sent, Err why it could not be resolved.
+	Skip string
+	Err  error
+}
+
+var escapedPlaceholderRE = regexp.MustCompile(`(?:%24|\$)%7B(.+?)%7D`)
+
+// DryRun resolves the requests of a suite in execution order without sending
+// them. Captured values are not known yet, so references to them stay as
+// `${name}` placeholders.
+func DryRun(cfg model.Config, tags []string) []PlannedRequest {
+	graph := BuildGraph(cfg.Tests, tags)
+	vars := map[string]any{}
+	for k, v := range cfg.Vars {
+		vars[k] = v
+	}
+
+	planned := []PlannedRequest{}
+	sent := map[string]bool{}
+	for wave, nodes := range graph.Waves() {
+		captured := []string{}
+		for _, node := range nodes {
+			test := node.Test
+			plan := PlannedRequest{Test: test, Wave: wave}
+			switch {
+			case node.Unselected:
+				plan.Skip = fmt.Sprintf("dependency %q is not selected in this run", test.After)
+			case test.After != "" && !sent[test.After]:
+				plan.Skip = fmt.Sprintf("dependency %q would not run", test.After)
+			default:
+				plan.Request, plan.Err = prepareDryRun(test, cfg, utils.Seeded(vars, randomScope(cfg.File, test.Name)))
+			}
+			if plan.Skip == "" && plan.Err == nil {
+				sent[test.Name] = true
+				captured = append(captured, sortedKeys(test.Capture)...)
+			}
+			planned = append(planned, plan)
+		}
+		for _, key := range captured {
+			vars[key] = utils.Placeholder(key)
+		}
+	}
+	for _, node := range graph.Cyclic() {
+		planned = append(planned, PlannedRequest{Test: node.Test, Wave: -1, Skip: "dependency cycle detected"})
+	}
+	return planned
+}
+
+func prepareDryRun(test model.TestCase, cfg model.Config, vars map[string]any) (httpx.Prepared, error) {
+	request, err := resolveRequest(test, cfg, vars)
+	if err != nil {
+		return httpx.Prepared{}, err
+	}
+	prepared, err := httpx.Prepare(request.Options)
+	if err != nil {
+		return httpx.Prepared{}, err
+	}
+	// Basic auth is only encoded here; mask the header as a real run does.
+	if auth := prepared.Header.Get("Authorization"); !hasPlaceholder(auth) {
+		secrets.AddCredential(auth)
+	}
+	// Adding the query re-encodes the URL; keep placeholders readable.
+	prepared.URL = escapedPlaceholderRE.ReplaceAllStringFunc(prepared.URL, func(match string) string {
+		inner := escapedPlaceholderRE.FindStringSubmatch(match)[1]
+		if name, err := url.PathUnescape(inner); err == nil {
+			return "${" + name + "}"
+		}
+		return match
+	})
+	return prepared, nil
+}
+
+// hasPlaceholder reports whether a dry run left a capture unresolved in
+// value; such values are not real credentials and must stay readable.
+func hasPlaceholder(value string) bool {
+	return strings.Contains(value, "${")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
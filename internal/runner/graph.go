@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+// Graph is the dependency DAG RunFile schedules. Tests run in waves: a wave
+// starts once every test it depends on (`after`) finished in an earlier wave.
+type Graph struct {
+	Nodes []GraphNode
+}
+
+type GraphNode struct {
+	Test model.TestCase
+	// Wave is the 0-based batch the test runs in when its dependencies pass,
+	// or -1 when it is part of, or waits on, a dependency cycle.
+	Wave int
+	// Unselected is set when `after` names a test that the tag filter dropped.
+	Unselected bool
+}
+
+// BuildGraph selects the tests matching tags and orders them into waves.
+func BuildGraph(tests []model.TestCase, tags []string) Graph {
+	selected := filterByTags(tests, tags)
+	index := make(map[string]int, len(selected))
+	for i, test := range selected {
+		index[test.Name] = i
+	}
+
+	graph := Graph{Nodes: make([]GraphNode, len(selected))}
+	const pending, visiting = -2, -3
+	for i, test := range selected {
+		graph.Nodes[i] = GraphNode{Test: test, Wave: pending}
+	}
+	var wave func(i int) int
+	wave = func(i int) int {
+		node := &graph.Nodes[i]
+		switch node.Wave {
+		case pending:
+		case visiting:
+			return -1
+		default:
+			return node.Wave
+		}
+		after := strings.TrimSpace(node.Test.After)
+		dep, ok := index[after]
+		switch {
+		case after == "":
+			node.Wave = 0
+		case !ok:
+			node.Wave = 0
+			node.Unselected = true
+		default:
+			node.Wave = visiting
+			parent := wave(dep)
+			if parent < 0 {
+				node.Wave = -1
+			} else {
+				node.Wave = parent + 1
+			}
+		}
+		return node.Wave
+	}
+	for i := range graph.Nodes {
+		wave(i)
+	}
+	return graph
+}
+
+// Waves groups the schedulable nodes by wave, keeping file order within each.
+func (g Graph) Waves() [][]GraphNode {
+	var waves [][]GraphNode
+	for _, node := range g.Nodes {
+		if node.Wave < 0 {
+			continue
+		}
+		for len(waves) <= node.Wave {
+			waves = append(waves, nil)
+		}
+		waves[node.Wave] = append(waves[node.Wave], node)
+	}
+	return waves
+}
+
+// Cyclic returns the nodes that can never run because of a dependency cycle.
+func (g Graph) Cyclic() []GraphNode {
+	var out []GraphNode
+	for _, node := range g.Nodes {
+		if node.Wave < 0 {
+			out = append(out, node)
+		}
+	}
+	return out
+}
+
+// Chain returns the `after` ancestors of name, nearest first.
+func (g Graph) Chain(name string) []string {
+	byName := make(map[string]model.TestCase, len(g.Nodes))
+	for _, node := range g.Nodes {
+		byName[node.Test.Name] = node.Test
+	}
+	var chain []string
+	seen := map[string]bool{name: true}
+	for test, ok := byName[name]; ok && test.After != ""; test, ok = byName[test.After] {
+		if seen[test.After] {
+			break
+		}
+		seen[test.After] = true
+		chain = append(chain, test.After)
+	}
+	return chain
+}
+
+// Related extends selected with the tests that run `after` one of them and
+// with the `after` chains all of those need, since captured values only
+// exist once the tests capturing them ran.
+func Related(tests []model.TestCase, selected map[string]bool) map[string]bool {
+	after := map[string]string{}
+	for _, test := range tests {
+		after[test.Name] = test.After
+	}
+	dependsOnSelected := func(name string) bool {
+		seen := map[string]bool{}
+		for dep := after[name]; dep != "" && !seen[dep]; dep = after[dep] {
+			if selected[dep] {
+				return true
+			}
+			seen[dep] = true
+		}
+		return false
+	}
+	out := map[string]bool{}
+	for _, test := range tests {
+		if selected[test.Name] || dependsOnSelected(test.Name) {
+			out[test.Name] = true
+		}
+	}
+	for name := range out {
+		seen := map[string]bool{}
+		for dep := after[name]; dep != "" && !seen[dep]; dep = after[dep] {
+			seen[dep] = true
+			if _, ok := after[dep]; ok {
+				out[dep] = true
+			}
+		}
+	}
+	return out
+}
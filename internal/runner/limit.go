@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+// StopError is the cause a run context is cancelled with; Why says what
+// stopped the run. Requests in flight get Grace to complete; a test whose
+// request is aborted is reported with Status as "<Verb>: <Why>", and a test
+// that had not started with Pending as "not run: <Why>".
+type StopError struct {
+	Why     string
+	Status  model.TestStatus
+	Verb    string
+	Pending model.TestStatus
+	Grace   time.Duration
+}
+
+func (e *StopError) Error() string {
+	return e.Why
+}
+
+// FailureLimit stops a run once a number of tests have failed. It is shared
+// by every suite of the run and cancels the run context, which aborts
+// in-flight requests and skips the tests that have not started.
+type FailureLimit struct {
+	mu     sync.Mutex
+	max    int
+	failed int
+	cancel context.CancelCauseFunc
+}
+
+// NewFailureLimit derives the run context from parent. max <= 0 means no
+// limit; the context is still cancellable through the returned limit.
+func NewFailureLimit(parent context.Context, max int) (context.Context, *FailureLimit) {
+	ctx, cancel := context.WithCancelCause(parent)
+	return ctx, &FailureLimit{max: max, cancel: cancel}
+}
+
+// Record counts result and cancels the run when the limit is reached.
+func (l *FailureLimit) Record(result model.TestResult) {
+	if l == nil || l.max <= 0 || result.Status != model.StatusFail {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failed++
+	if l.failed == l.max {
+		why := fmt.Sprintf("stopped after %d failures", l.max)
+		if l.max == 1 {
+			why = "stopped after the first failure"
+		}
+		l.cancel(&StopError{Why: why, Status: model.StatusCancelled, Verb: "aborted", Pending: model.StatusSkip})
+	}
+}
+
+// Stop releases the context once the run is over.
+func (l *FailureLimit) Stop() {
+	if l != nil {
+		l.cancel(context.Canceled)
+	}
+}
+
+// notRunResult reports a test the stopped run did not start.
+func notRunResult(ctx context.Context, test model.TestCase) model.TestResult {
+	cause := context.Cause(ctx)
+	var stop *StopError
+	if errors.As(cause, &stop) {
+		return newResult(test, stop.Pending, "not run: "+stop.Why)
+	}
+	return newResult(test, model.StatusSkip, "not run: "+cause.Error())
+}
+
+// abortedResult reports a test whose request the stopped run aborted.
+func abortedResult(ctx context.Context, test model.TestCase) model.TestResult {
+	cause := context.Cause(ctx)
+	var stop *StopError
+	if errors.As(cause, &stop) {
+		return newResult(test, stop.Status, stop.Verb+": "+stop.Why)
+	}
+	return newResult(test, model.StatusCancelled, "aborted: "+cause.Error())
+}
+
+// requestContext is the context requests are sent with: it follows ctx, but
+// when ctx is stopped with a grace period, requests in flight get that long
+// to finish first.
+func requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	out, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		var stop *StopError
+		if errors.As(context.Cause(ctx), &stop) && stop.Grace > 0 {
+			timer := time.NewTimer(stop.Grace)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-out.Done():
+				return
+			}
+		}
+		cancel(context.Cause(ctx))
+	})
+	return out, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
@@ -1,14 +1,18 @@
 package runner
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/DevrajJain04/reqres/internal/assertion"
+	"github.com/DevrajJain04/reqres/internal/events"
 	"github.com/DevrajJain04/reqres/internal/httpx"
 	"github.com/DevrajJain04/reqres/internal/model"
+	"github.com/DevrajJain04/reqres/internal/secrets"
 	"github.com/DevrajJain04/reqres/internal/snapshot"
 	"github.com/DevrajJain04/reqres/internal/utils"
 )
@@ -18,32 +22,35 @@ type FileRunOptions struct {
 	Config          model.Config
 	RunOptions      model.RunOptions
 	SnapshotManager *snapshot.Manager
+	Events          events.Sink
+	// Context cancels the run: in-flight requests are aborted and tests that
+	// have not started are not run, as described by its StopError cause.
+	Context  context.Context
+	Failures *FailureLimit
 }
 
 func RunFile(opts FileRunOptions) (model.FileReport, int) {
 	started := time.Now()
 	cfg := opts.Config
 	runOpts := opts.RunOptions
+	sink := events.OrNop(opts.Events)
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	tests := filterByTags(cfg.Tests, runOpts.Tags)
+	graph := BuildGraph(cfg.Tests, runOpts.Tags)
 	report := model.FileReport{
 		File:  opts.FilePath,
 		Tests: []model.TestResult{},
 	}
-	if len(tests) == 0 {
+	sink.Emit(events.Event{Type: events.FileStart, File: opts.FilePath, Total: len(graph.Nodes)})
+	if len(graph.Nodes) == 0 {
 		report.Duration = time.Since(started).Milliseconds()
+		sink.Emit(fileEndEvent(report))
 		return report, 0
 	}
 
-	testOrder := make([]string, 0, len(tests))
-	testByName := map[string]model.TestCase{}
-	unresolved := map[string]bool{}
-	for _, test := range tests {
-		testOrder = append(testOrder, test.Name)
-		testByName[test.Name] = test
-		unresolved[test.Name] = true
-	}
-
 	varsMu := sync.RWMutex{}
 	vars := map[string]any{}
 	for k, v := range cfg.Vars {
@@ -52,86 +59,64 @@ func RunFile(opts FileRunOptions) (model.FileReport, int) {
 
 	resultsByName := map[string]model.TestResult{}
 	snapshotsSaved := 0
+	record := func(result model.TestResult) {
+		resultsByName[result.Name] = result
+		sink.Emit(events.Result(opts.FilePath, result))
+	}
+	skip := func(test model.TestCase, message string) {
+		record(newResult(test, model.StatusSkip, message))
+	}
 
-	for len(unresolved) > 0 {
-		// Resolve a ready batch where all dependency links (`after`) are satisfied.
+	// Each wave only starts once the `after` dependencies of its tests have run.
+	for _, wave := range graph.Waves() {
 		ready := []model.TestCase{}
-		progress := false
-
-		for _, name := range testOrder {
-			if !unresolved[name] {
-				continue
-			}
-			test := testByName[name]
-			if strings.TrimSpace(test.After) == "" {
-				ready = append(ready, test)
-				continue
-			}
-
-			depResult, ok := resultsByName[test.After]
-			if !ok {
-				if _, depSelected := unresolved[test.After]; depSelected {
-					continue
-				}
-				resultsByName[test.Name] = model.TestResult{
-					Name:    test.Name,
-					Method:  effectiveMethod(test.Method),
-					Path:    test.Path,
-					Status:  model.StatusSkip,
-					Message: fmt.Sprintf("dependency %q is not selected in this run", test.After),
-				}
-				delete(unresolved, test.Name)
-				progress = true
-				continue
-			}
-
-			if depResult.Status == model.StatusPass {
+		for _, node := range wave {
+			test := node.Test
+			switch {
+			case ctx.Err() != nil:
+				record(notRunResult(ctx, test))
+			case node.Unselected:
+				skip(test, fmt.Sprintf("dependency %q is not selected in this run", test.After))
+			case test.After != "" && resultsByName[test.After].Status != model.StatusPass:
+				skip(test, fmt.Sprintf("dependency %q did not pass", test.After))
+			default:
 				ready = append(ready, test)
-			} else {
-				resultsByName[test.Name] = model.TestResult{
-					Name:    test.Name,
-					Method:  effectiveMethod(test.Method),
-					Path:    test.Path,
-					Status:  model.StatusSkip,
-					Message: fmt.Sprintf("dependency %q did not pass", test.After),
-				}
-				delete(unresolved, test.Name)
-				progress = true
 			}
 		}
-
 		if len(ready) == 0 {
-			if !progress {
-				for _, name := range testOrder {
-					if !unresolved[name] {
-						continue
-					}
-					test := testByName[name]
-					resultsByName[test.Name] = model.TestResult{
-						Name:    test.Name,
-						Method:  effectiveMethod(test.Method),
-						Path:    test.Path,
-						Status:  model.StatusFail,
-						Message: "dependency cycle detected",
-					}
-					delete(unresolved, name)
-				}
-			}
 			continue
 		}
 
 		batchResults, saved := runBatch(ready, max(1, runOpts.Parallel), func(test model.TestCase) model.TestResult {
-			return executeTest(test, opts.FilePath, cfg, runOpts, &varsMu, vars, opts.SnapshotManager)
+			if ctx.Err() != nil {
+				result := notRunResult(ctx, test)
+				sink.Emit(events.Result(opts.FilePath, result))
+				return result
+			}
+			sink.Emit(events.Event{
+				Type:   events.TestStart,
+				File:   opts.FilePath,
+				Test:   test.Name,
+				Method: effectiveMethod(test.Method),
+				Path:   test.Path,
+			})
+			result := executeTest(ctx, test, opts.FilePath, cfg, runOpts, &varsMu, vars, opts.SnapshotManager)
+			opts.Failures.Record(result)
+			sink.Emit(events.Result(opts.FilePath, result))
+			return result
 		})
 		snapshotsSaved += saved
 		for _, result := range batchResults {
 			resultsByName[result.Name] = result
-			delete(unresolved, result.Name)
 		}
 	}
+	for _, node := range graph.Cyclic() {
+		resultsByName[node.Test.Name] = newResult(node.Test, model.StatusFail, "dependency cycle detected")
+		sink.Emit(events.Result(opts.FilePath, resultsByName[node.Test.Name]))
+	}
 
-	for _, name := range testOrder {
-		result := resultsByName[name]
+	for _, node := range graph.Nodes {
+		result := resultsByName[node.Test.Name]
 		report.Tests = append(report.Tests, result)
 		switch result.Status {
 		case model.StatusPass:
@@ -140,14 +125,31 @@ func RunFile(opts FileRunOptions) (model.FileReport, int) {
 			report.Failed++
 		case model.StatusSkip:
 			report.Skipped++
+		case model.StatusCancelled:
+			report.Cancelled++
 		}
 	}
 	report.Total = len(report.Tests)
 	report.Duration = time.Since(started).Milliseconds()
+	sink.Emit(fileEndEvent(report))
 	return report, snapshotsSaved
 }
 
+func fileEndEvent(report model.FileReport) events.Event {
+	return events.Event{
+		Type:       events.FileEnd,
+		File:       report.File,
+		Total:      report.Total,
+		Passed:     report.Passed,
+		Failed:     report.Failed,
+		Skipped:    report.Skipped,
+		Cancelled:  report.Cancelled,
+		DurationMS: report.Duration,
+	}
+}
+
 func executeTest(
+	ctx context.Context,
 	test model.TestCase,
 	filePath string,
 	cfg model.Config,
@@ -157,105 +159,49 @@ func executeTest(
 	snapshots *snapshot.Manager,
 ) model.TestResult {
 	started := time.Now()
-	result := model.TestResult{
-		Name:   test.Name,
-		Method: effectiveMethod(test.Method),
-		Path:   test.Path,
-		Status: model.StatusFail,
-	}
+	result := newResult(test, model.StatusFail, "")
+	result.StartedAt = started
 
-	mergedHeaders := mergeHeaders(cfg.Defaults.Headers, test.Headers)
-	auth := strings.TrimSpace(test.Auth)
-	if auth == "" {
-		auth = cfg.Defaults.Auth
-	}
-
-	timeoutMS := cfg.Timeout
-	if test.TimeoutMS != nil {
-		timeoutMS = *test.TimeoutMS
-	}
 	retries := cfg.Retries
 	if test.Retries != nil {
 		retries = *test.Retries
 	}
 
-	varsSnapshot := map[string]any{}
 	varsMu.RLock()
-	for k, v := range vars {
-		varsSnapshot[k] = v
-	}
+	varsSnapshot := utils.Seeded(vars, randomScope(filePath, test.Name))
 	varsMu.RUnlock()
 
-	path, err := utils.ExpandString(test.Path, varsSnapshot)
-	if err != nil {
-		result.Message = err.Error()
-		result.DurationMS = time.Since(started).Milliseconds()
-		return result
-	}
-	result.Path = path
-
-	auth, err = utils.ExpandString(auth, varsSnapshot)
-	if err != nil {
-		result.Message = err.Error()
-		result.DurationMS = time.Since(started).Milliseconds()
-		return result
-	}
-
-	expandedHeaders := map[string]string{}
-	for key, value := range mergedHeaders {
-		expanded, err := utils.ExpandString(value, varsSnapshot)
-		if err != nil {
-			result.Message = err.Error()
-			result.DurationMS = time.Since(started).Milliseconds()
-			return result
-		}
-		expandedHeaders[key] = expanded
-	}
-
-	queryAny, err := utils.ExpandAny(test.Query, varsSnapshot)
+	request, err := resolveRequest(test, cfg, varsSnapshot)
+	result.Path = request.Path
 	if err != nil {
 		result.Message = err.Error()
 		result.DurationMS = time.Since(started).Milliseconds()
 		return result
 	}
-	query := utils.ToStringMap(queryAny)
 
-	bodyAny, err := utils.ExpandAny(test.Body, varsSnapshot)
-	if err != nil {
-		result.Message = err.Error()
+	if ctx.Err() != nil {
+		result = notRunResult(ctx, test)
+		result.Path = request.Path
 		result.DurationMS = time.Since(started).Milliseconds()
 		return result
 	}
 
-	expandedCheck, err := utils.ExpandAny(test.Check, varsSnapshot)
-	if err != nil {
-		result.Message = err.Error()
-		result.DurationMS = time.Since(started).Milliseconds()
-		return result
-	}
-
-	url := joinURL(cfg.Base, path)
 	attempts := 0
 	var lastErr error
 	var lastResp httpx.Response
 	// Retry wraps both transport and assertion failures so flaky network/status paths can recover.
-	for attempt := 0; attempt <= max(0, retries); attempt++ {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+	// A stopped run lets the attempt in flight finish but starts no retry.
+	for attempt := 0; attempt <= max(0, retries) && (attempt == 0 || ctx.Err() == nil); attempt++ {
 		attempts++
-		resp, reqErr := httpx.Do(httpx.RequestOptions{
-			Method:  result.Method,
-			URL:     url,
-			Headers: expandedHeaders,
-			Query:   query,
-			Body:    bodyAny,
-			Auth:    auth,
-			Timeout: time.Duration(timeoutMS) * time.Millisecond,
-		})
+		resp, reqErr := httpx.Do(reqCtx, request.Options)
 		lastResp = resp
 		if reqErr != nil {
 			lastErr = reqErr
 			continue
 		}
-		if assertErr := assertion.Evaluate(expandedCheck, resp.StatusCode, resp.Headers, resp.BodyJSON); assertErr != nil {
+		if assertErr := assertion.Evaluate(request.Check, resp.StatusCode, resp.Headers, resp.BodyJSON); assertErr != nil {
 			lastErr = assertErr
 			continue
 		}
@@ -265,7 +211,19 @@ func executeTest(
 
 	result.Attempts = attempts
 	result.StatusCode = lastResp.StatusCode
+	result.Request = requestDetail(lastResp.Request)
+	if lastResp.StatusCode != 0 {
+		result.Response = responseDetail(lastResp)
+	}
 
+	if lastErr != nil && reqCtx.Err() != nil {
+		// The run was stopped and the request aborted while in flight.
+		stopped := abortedResult(ctx, test)
+		result.Status = stopped.Status
+		result.Message = stopped.Message
+		result.DurationMS = time.Since(started).Milliseconds()
+		return result
+	}
 	if lastErr != nil {
 		result.Status = model.StatusFail
 		result.Message = lastErr.Error()
@@ -290,6 +248,9 @@ func executeTest(
 				return result
 			}
 
+			if isSecret(cfg, key) {
+				secrets.Add(utils.ToString(value))
+			}
 			varsMu.Lock()
 			vars[key] = value
 			varsMu.Unlock()
@@ -312,6 +273,81 @@ func executeTest(
 	return result
 }
 
+type resolvedRequest struct {
+	Path    string
+	Options httpx.RequestOptions
+	Check   any
+}
+
+// resolveRequest applies defaults, auth and variable expansion to test the
+// way it is sent. Path is set as soon as it is expanded, even on error.
+func resolveRequest(test model.TestCase, cfg model.Config, vars map[string]any) (resolvedRequest, error) {
+	var out resolvedRequest
+	mergedHeaders := mergeHeaders(cfg.Defaults.Headers, test.Headers)
+	auth := strings.TrimSpace(test.Auth)
+	if auth == "" {
+		auth = cfg.Defaults.Auth
+	}
+
+	timeoutMS := cfg.Timeout
+	if test.TimeoutMS != nil {
+		timeoutMS = *test.TimeoutMS
+	}
+
+	path, err := utils.ExpandString(test.Path, vars)
+	if err != nil {
+		return out, err
+	}
+	out.Path = path
+
+	auth, err = utils.ExpandString(auth, vars)
+	if err != nil {
+		return out, err
+	}
+	if auth != "" && !hasPlaceholder(auth) {
+		secrets.AddCredential(auth)
+	}
+
+	expandedHeaders := map[string]string{}
+	for _, key := range sortedKeys(mergedHeaders) {
+		value := mergedHeaders[key]
+		expanded, err := utils.ExpandString(value, vars)
+		if err != nil {
+			return out, err
+		}
+		if strings.EqualFold(key, "Authorization") && !hasPlaceholder(expanded) {
+			secrets.AddCredential(expanded)
+		}
+		expandedHeaders[key] = expanded
+	}
+
+	queryAny, err := utils.ExpandAny(test.Query, vars)
+	if err != nil {
+		return out, err
+	}
+
+	bodyAny, err := utils.ExpandAny(test.Body, vars)
+	if err != nil {
+		return out, err
+	}
+
+	out.Check, err = utils.ExpandAny(test.Check, vars)
+	if err != nil {
+		return out, err
+	}
+
+	out.Options = httpx.RequestOptions{
+		Method:  effectiveMethod(test.Method),
+		URL:     joinURL(cfg.Base, path),
+		Headers: expandedHeaders,
+		Query:   utils.ToStringMap(queryAny),
+		Body:    bodyAny,
+		Auth:    auth,
+		Timeout: time.Duration(timeoutMS) * time.Millisecond,
+	}
+	return out, nil
+}
+
 func runBatch(tests []model.TestCase, parallel int, run func(model.TestCase) model.TestResult) ([]model.TestResult, int) {
 	if parallel <= 1 || len(tests) <= 1 {
 		out := make([]model.TestResult, 0, len(tests))
@@ -410,6 +446,74 @@ func mergeHeaders(a, b map[string]string) map[string]string {
 	return out
 }
 
+func newResult(test model.TestCase, status model.TestStatus, message string) model.TestResult {
+	return model.TestResult{
+		Name:    test.Name,
+		Method:  effectiveMethod(test.Method),
+		Path:    test.Path,
+		Status:  status,
+		Message: message,
+		Source:  test.Pos.File,
+		Line:    test.Pos.Line,
+		Tags:    test.Tags,
+		After:   test.After,
+	}
+}
+
+// maxDetailBody caps the request/response bodies kept in reports.
+const maxDetailBody = 8 << 10
+
+func requestDetail(req httpx.Prepared) *model.RequestDetail {
+	if req.URL == "" {
+		return nil
+	}
+	secrets.AddCredential(req.Header.Get("Authorization"))
+	body, _ := truncateBody(req.Body)
+	return &model.RequestDetail{
+		Method:  req.Method,
+		URL:     req.URL,
+		Headers: flattenHeaders(req.Header),
+		Body:    body,
+	}
+}
+
+func responseDetail(resp httpx.Response) *model.ResponseDetail {
+	body, truncated := truncateBody(resp.BodyBytes)
+	return &model.ResponseDetail{
+		StatusCode: resp.StatusCode,
+		Headers:    flattenHeaders(resp.Headers),
+		Body:       body,
+		Truncated:  truncated,
+	}
+}
+
+func flattenHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for key, values := range header {
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func truncateBody(body []byte) (string, bool) {
+	if len(body) <= maxDetailBody {
+		return string(body), false
+	}
+	return string(body[:maxDetailBody]), true
+}
+
+func isSecret(cfg model.Config, name string) bool {
+	for _, secret := range cfg.Secrets {
+		if secret == name {
+			return true
+		}
+	}
+	return false
+}
+
 func joinURL(base string, path string) string {
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		return path
@@ -430,3 +534,8 @@ func max(a int, b int) int {
 	}
 	return b
 }
+
+// randomScope names the random source of a test, see utils.Seeded.
+func randomScope(file string, test string) string {
+	return file + "::" + test
+}
@@ -0,0 +1,182 @@
+package secrets
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/DevrajJain04/reqres/internal/model"
+)
+
+const Mask = "****"
+
+var (
+	mu     sync.RWMutex
+	values = map[string]struct{}{}
+)
+
+// Add registers a value that must never be printed or written to reports.
+func Add(value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	mu.Lock()
+	values[value] = struct{}{}
+	mu.Unlock()
+}
+
+// AddCredential registers the credential part of an auth string or
+// Authorization header ("bearer <token>", "Basic <encoded>").
+func AddCredential(auth string) {
+	parts := strings.SplitN(strings.TrimSpace(auth), " ", 2)
+	if len(parts) == 2 {
+		Add(strings.TrimSpace(parts[1]))
+		return
+	}
+	Add(auth)
+}
+
+// Reset forgets every registered value.
+func Reset() {
+	mu.Lock()
+	values = map[string]struct{}{}
+	mu.Unlock()
+}
+
+// Redact replaces every registered value in input with the mask.
+func Redact(input string) string {
+	if input == "" {
+		return input
+	}
+	mu.RLock()
+	if len(values) == 0 {
+		mu.RUnlock()
+		return input
+	}
+	list := make([]string, 0, len(values))
+	for value := range values {
+		list = append(list, value)
+	}
+	mu.RUnlock()
+
+	// Mark every byte that belongs to a secret first, so a secret containing
+	// or overlapping another one is masked whole instead of leaving a piece.
+	var covered []bool
+	for _, value := range list {
+		for start := 0; ; start++ {
+			i := strings.Index(input[start:], value)
+			if i < 0 {
+				break
+			}
+			if covered == nil {
+				covered = make([]bool, len(input))
+			}
+			start += i
+			for j := start; j < start+len(value); j++ {
+				covered[j] = true
+			}
+		}
+	}
+	if covered == nil {
+		return input
+	}
+	var b strings.Builder
+	for i := 0; i < len(input); {
+		if !covered[i] {
+			b.WriteByte(input[i])
+			i++
+			continue
+		}
+		b.WriteString(Mask)
+		for i < len(input) && covered[i] {
+			i++
+		}
+	}
+	return b.String()
+}
+
+// RedactReport returns a copy of data with every string field redacted.
+func RedactReport(data model.RunReport) model.RunReport {
+	out := data
+	out.Flaky = redactSlice(data.Flaky)
+
+	out.Files = make([]model.FileReport, len(data.Files))
+	for i, file := range data.Files {
+		copied := file
+		copied.File = Redact(file.File)
+		copied.Tests = make([]model.TestResult, len(file.Tests))
+		for j, test := range file.Tests {
+			copied.Tests[j] = RedactResult(test)
+		}
+		out.Files[i] = copied
+	}
+
+	if len(data.Failures) > 0 {
+		out.Failures = make([]model.FailureEntry, len(data.Failures))
+		for i, failure := range data.Failures {
+			out.Failures[i] = model.FailureEntry{
+				File:   Redact(failure.File),
+				Source: Redact(failure.Source),
+				Line:   failure.Line,
+				Test:   Redact(failure.Test),
+				Why:    Redact(failure.Why),
+			}
+		}
+	}
+
+	if data.Load != nil {
+		load := *data.Load
+		load.Path = Redact(load.Path)
+		out.Load = &load
+	}
+	return out
+}
+
+// RedactResult returns a copy of a single test result with secrets masked.
+func RedactResult(test model.TestResult) model.TestResult {
+	out := test
+	out.Name = Redact(test.Name)
+	out.Path = Redact(test.Path)
+	out.Message = Redact(test.Message)
+	if test.Captures != nil {
+		out.Captures = map[string]string{}
+		for k, v := range test.Captures {
+			out.Captures[k] = Redact(v)
+		}
+	}
+	if test.Request != nil {
+		req := *test.Request
+		req.URL = Redact(req.URL)
+		req.Headers = redactMap(req.Headers)
+		req.Body = Redact(req.Body)
+		out.Request = &req
+	}
+	if test.Response != nil {
+		resp := *test.Response
+		resp.Headers = redactMap(resp.Headers)
+		resp.Body = Redact(resp.Body)
+		out.Response = &resp
+	}
+	return out
+}
+
+func redactMap(items map[string]string) map[string]string {
+	if items == nil {
+		return nil
+	}
+	out := map[string]string{}
+	for k, v := range items {
+		out[k] = Redact(v)
+	}
+	return out
+}
+
+func redactSlice(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = Redact(item)
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package secrets
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		input   string
+		want    string
+	}{
+		{"none registered", nil, "token abc", "token abc"},
+		{"no match", []string{"abc"}, "token xyz", "token xyz"},
+		{"every occurrence", []string{"abc"}, "abc and abc", "**** and ****"},
+		{"longest first", []string{"abc", "abcdef"}, "key=abcdef", "key=****"},
+		{"shorter inside longer", []string{"cd", "abcdef"}, "abcdef cd", "**** ****"},
+		{"overlap", []string{"abcd", "cdef"}, "xabcdefx", "x****x"},
+		{"overlapping occurrences", []string{"aba"}, "ababa", "****"},
+		{"blank ignored", []string{" ", ""}, "a b", "a b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Reset()
+			t.Cleanup(Reset)
+			for _, value := range tt.secrets {
+				Add(value)
+			}
+			if got := Redact(tt.input); got != tt.want {
+				t.Fatalf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddCredential(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	AddCredential("Bearer tok123")
+	if got := Redact("Authorization: Bearer tok123"); got != "Authorization: Bearer ****" {
+		t.Fatalf("got %q", got)
+	}
+}
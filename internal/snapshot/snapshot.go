@@ -28,7 +28,13 @@ func (m *Manager) Evaluate(filePath string, testName string, snapshotSpec any, b
 		return false, nil
 	}
 
-	suite := utils.SanitizeFileName(strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)))
+	// Documents of a multi-document file (`suite.yaml#2`) get their own directory.
+	base, doc, _ := strings.Cut(filepath.Base(filePath), "#")
+	suite := strings.TrimSuffix(base, filepath.Ext(base))
+	if doc != "" {
+		suite += "_" + doc
+	}
+	suite = utils.SanitizeFileName(suite)
 	targetDir := filepath.Join(m.BaseDir, suite)
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
 		return false, fmt.Errorf("create snapshot dir: %w", err)
@@ -67,6 +67,8 @@ func ToString(value any) string {
 		return ""
 	case string:
 		return t
+	case Placeholder:
+		return t.String()
 	case int:
 		return strconv.Itoa(t)
 	case int64:
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// function is a placeholder function. vars are the variables of the
+// expansion, which carry the random source of the test being resolved.
+type function func(vars map[string]any, args []any) (any, error)
+
+// functions are callable from placeholders, e.g. `${randInt(1, 100)}`.
+var functions = map[string]function{
+	"uuid": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("uuid", args, 0, 0); err != nil {
+			return nil, err
+		}
+		var b [16]byte
+		withRand(vars, func(r *rand.Rand) { r.Read(b[:]) })
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	},
+	"randInt": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("randInt", args, 2, 2); err != nil {
+			return nil, err
+		}
+		lo, err := intArg("randInt", args, 0)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := intArg("randInt", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("randInt: max %d is below min %d", hi, lo)
+		}
+		var n int
+		withRand(vars, func(r *rand.Rand) { n = lo + r.Intn(hi-lo+1) })
+		return n, nil
+	},
+	"randString": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("randString", args, 1, 1); err != nil {
+			return nil, err
+		}
+		n, err := intArg("randString", args, 0)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("randString: length must not be negative")
+		}
+		const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+		out := make([]byte, n)
+		withRand(vars, func(r *rand.Rand) {
+			for i := range out {
+				out[i] = alphabet[r.Intn(len(alphabet))]
+			}
+		})
+		return string(out), nil
+	},
+	"now": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("now", args, 0, 1); err != nil {
+			return nil, err
+		}
+		layout := time.RFC3339
+		if len(args) == 1 {
+			layout = ToString(args[0])
+		}
+		return time.Now().Format(layout), nil
+	},
+	"timestamp": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("timestamp", args, 0, 0); err != nil {
+			return nil, err
+		}
+		return int(time.Now().Unix()), nil
+	},
+	"base64": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("base64", args, 1, 1); err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString([]byte(ToString(args[0]))), nil
+	},
+	"sha256": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("sha256", args, 1, 1); err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256([]byte(ToString(args[0])))
+		return hex.EncodeToString(sum[:]), nil
+	},
+	"env": func(vars map[string]any, args []any) (any, error) {
+		if err := arity("env", args, 1, 1); err != nil {
+			return nil, err
+		}
+		return os.Getenv(ToString(args[0])), nil
+	},
+}
+
+var (
+	randMu     sync.Mutex
+	randSeed   = time.Now().UnixNano()
+	randSource = rand.New(rand.NewSource(randSeed))
+	randUsed   bool
+	// randScopes counts how often each scope was seeded.
+	randScopes = map[string]int{}
+)
+
+// SetSeed makes uuid(), randInt() and randString() reproducible.
+func SetSeed(seed int64) {
+	randMu.Lock()
+	defer randMu.Unlock()
+	randSeed = seed
+	randSource = rand.New(rand.NewSource(seed))
+	randScopes = map[string]int{}
+}
+
+// RandomSeed returns the current seed and whether any random function ran.
+func RandomSeed() (int64, bool) {
+	randMu.Lock()
+	defer randMu.Unlock()
+	return randSeed, randUsed
+}
+
+// randKey holds the random source Seeded puts in vars. It is not a valid
+// variable name, so placeholders cannot reach it.
+const randKey = "\x00rand"
+
+// Seeded returns a copy of vars whose random functions draw from a source
+// derived from the run seed and scope (suite and test). Tests run in
+// parallel, so a shared source would hand out values in scheduling order
+// and --seed would not reproduce them. Seeding the same scope again, as
+// flaky detection and watch mode do, yields the next source in sequence.
+func Seeded(vars map[string]any, scope string) map[string]any {
+	randMu.Lock()
+	seed := randSeed
+	round := randScopes[scope]
+	randScopes[scope]++
+	randMu.Unlock()
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%s\x00%d", seed, scope, round)
+
+	out := make(map[string]any, len(vars)+1)
+	for k, v := range vars {
+		out[k] = v
+	}
+	out[randKey] = rand.New(rand.NewSource(int64(h.Sum64())))
+	return out
+}
+
+func withRand(vars map[string]any, fn func(r *rand.Rand)) {
+	randMu.Lock()
+	defer randMu.Unlock()
+	randUsed = true
+	if r, ok := vars[randKey].(*rand.Rand); ok {
+		fn(r)
+		return
+	}
+	fn(randSource)
+}
+
+func arity(name string, args []any, lo, hi int) error {
+	if len(args) < lo || len(args) > hi {
+		if lo == hi {
+			return fmt.Errorf("%s() takes %d argument(s), got %d", name, lo, len(args))
+		}
+		return fmt.Errorf("%s() takes %d to %d arguments, got %d", name, lo, hi, len(args))
+	}
+	return nil
+}
+
+func intArg(name string, args []any, i int) (int, error) {
+	switch t := args[i].(type) {
+	case int:
+		return t, nil
+	case float64:
+		if t == float64(int(t)) {
+			return int(t), nil
+		}
+	}
+	return 0, fmt.Errorf("%s() argument %d must be an integer, got %v", name, i+1, args[i])
+}
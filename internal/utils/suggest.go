@@ -0,0 +1,41 @@
+package utils
+
+import "strings"
+
+// Suggest returns the candidate closest to word, or "" when none is close
+// enough to be a likely typo.
+func Suggest(word string, candidates []string) string {
+	best, bestDist := "", -1
+	limit := 1
+	if len(word) > 3 {
+		limit = min(3, max(2, len(word)/3))
+	}
+	for _, candidate := range candidates {
+		dist := levenshtein(strings.ToLower(word), strings.ToLower(candidate))
+		if dist <= limit && (bestDist < 0 || dist < bestDist) {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -1,41 +1,568 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/DevrajJain04/reqres/internal/secrets"
 )
 
-var placeholderRE = regexp.MustCompile(`\$\{([a-zA-Z0-9_.-]+)\}`)
+const (
+	secretPrefix = "secret:"
+	envPrefix    = "env:"
+)
 
+var (
+	envNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	nameRE    = regexp.MustCompile(`^(?:secret:)?[a-zA-Z0-9_-][a-zA-Z0-9_.\[\]-]*$`)
+	callRE    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*\s*\(`)
+)
+
+// ExpandString replaces ${...} placeholders. A placeholder is a variable
+// name, `secret:name` (which also masks the value in output), `env:NAME` /
+// `env:NAME:-fallback` or a call to one of the built-in functions such as
+// `uuid()`. Anything else is left as is.
 func ExpandString(input string, vars map[string]any) (string, error) {
 	if input == "" {
 		return "", nil
 	}
+	var b strings.Builder
 	var missing []string
-	out := placeholderRE.ReplaceAllStringFunc(input, func(token string) string {
-		matches := placeholderRE.FindStringSubmatch(token)
-		if len(matches) != 2 {
-			return token
+	for rest := input; ; {
+		start := strings.Index(rest, "${")
+		end := -1
+		if start >= 0 {
+			end = placeholderEnd(rest, start+2)
 		}
-		name := matches[1]
-		value, ok := vars[name]
-		if !ok {
-			missing = append(missing, name)
-			return token
+		if end < 0 {
+			b.WriteString(rest)
+			break
 		}
-		return ToString(value)
-	})
+		b.WriteString(rest[:start])
+		token := rest[start : end+1]
+		value, ok, err := evalPlaceholder(rest[start+2:end], vars, &missing)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", token, err)
+		}
+		if ok {
+			b.WriteString(ToString(value))
+		} else {
+			b.WriteString(token)
+		}
+		rest = rest[end+1:]
+	}
 	if len(missing) > 0 {
 		return "", fmt.Errorf("missing vars: %s", strings.Join(uniqueStrings(missing), ", "))
 	}
-	return out, nil
+	return b.String(), nil
+}
+
+// ExpandEnv replaces only `${env:...}` placeholders, using lookup instead of
+// the process environment. References that are unset and have no fallback
+// stay in place and their names are returned.
+func ExpandEnv(input string, lookup func(string) (string, bool)) (string, []string) {
+	if !strings.Contains(input, "${"+envPrefix) {
+		return input, nil
+	}
+	var b strings.Builder
+	var unset []string
+	for rest := input; ; {
+		start := strings.Index(rest, "${")
+		end := -1
+		if start >= 0 {
+			end = placeholderEnd(rest, start+2)
+		}
+		if end < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:start])
+		token := rest[start : end+1]
+		name, fallback, hasFallback, ok := envRef(rest[start+2 : end])
+		switch value, set := lookup(name); {
+		case !ok:
+			b.WriteString(token)
+		case set:
+			b.WriteString(value)
+		case hasFallback:
+			b.WriteString(fallback)
+		default:
+			b.WriteString(token)
+			unset = append(unset, name)
+		}
+		rest = rest[end+1:]
+	}
+	return b.String(), uniqueStrings(unset)
+}
+
+// ExpandKnown replaces the variable placeholders that vars can resolve and
+// leaves everything else, including function calls, `${env:...}` and
+// values only a capture will provide, in place.
+func ExpandKnown(input string, vars map[string]any) string {
+	var b strings.Builder
+	for rest := input; ; {
+		start := strings.Index(rest, "${")
+		end := -1
+		if start >= 0 {
+			end = placeholderEnd(rest, start+2)
+		}
+		if end < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:start])
+		token := rest[start : end+1]
+		expr := rest[start+2 : end]
+		rest = rest[end+1:]
+		if _, _, _, ok := envRef(expr); ok || !nameRE.MatchString(expr) {
+			b.WriteString(token)
+			continue
+		}
+		value, ok, err := lookupVar(strings.TrimPrefix(expr, secretPrefix), vars)
+		if err != nil || !ok {
+			b.WriteString(token)
+			continue
+		}
+		if strings.HasPrefix(expr, secretPrefix) {
+			secrets.Add(ToString(value))
+		}
+		b.WriteString(ToString(value))
+	}
+	return b.String()
 }
 
+// References lists the variable names input refers to, without resolving
+// anything. `${env:...}` placeholders are not included. Unknown functions
+// and malformed calls are reported as errors.
+func References(input string) ([]string, error) {
+	var refs []string
+	for rest := input; ; {
+		start := strings.Index(rest, "${")
+		end := -1
+		if start >= 0 {
+			end = placeholderEnd(rest, start+2)
+		}
+		if end < 0 {
+			break
+		}
+		token := rest[start : end+1]
+		expr := rest[start+2 : end]
+		rest = rest[end+1:]
+		if _, _, _, ok := envRef(expr); ok {
+			continue
+		}
+		switch {
+		case nameRE.MatchString(expr):
+			refs = append(refs, strings.TrimPrefix(expr, secretPrefix))
+		case callRE.MatchString(expr):
+			p := &exprParser{src: expr, refs: &refs}
+			if _, err := p.parse(); err != nil {
+				return refs, fmt.Errorf("%s: %w", token, err)
+			}
+		}
+	}
+	return uniqueStrings(refs), nil
+}
+
+// LookupVar resolves a dotted or indexed reference such as `user.tags[0]`
+// against vars. ok is false when the root variable is not defined; err
+// says why the rest of the path cannot be walked.
+func LookupVar(name string, vars map[string]any) (any, bool, error) {
+	return lookupVar(name, vars)
+}
+
+// RootVar returns the variable a dotted or indexed reference starts from.
+func RootVar(name string) string {
+	if i := strings.IndexAny(name, ".["); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// envRef splits `env:NAME` and `env:NAME:-fallback`.
+func envRef(expr string) (name string, fallback string, hasFallback bool, ok bool) {
+	rest, found := strings.CutPrefix(expr, envPrefix)
+	if !found {
+		return "", "", false, false
+	}
+	name, fallback, hasFallback = strings.Cut(rest, ":-")
+	return name, fallback, hasFallback, envNameRE.MatchString(name)
+}
+
+// placeholderEnd returns the index of the `}` closing the placeholder whose
+// body starts at i, or -1. Quotes only count inside call arguments.
+func placeholderEnd(s string, i int) int {
+	parens, braces := 0, 0
+	var quote byte
+	for j := i; j < len(s); j++ {
+		c := s[j]
+		if quote != 0 {
+			switch {
+			case c == '\\' && quote == '"':
+				j++
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '(':
+			parens++
+		case ')':
+			parens--
+		case '\'', '"':
+			if parens > 0 {
+				quote = c
+			}
+		case '{':
+			braces++
+		case '}':
+			if braces == 0 {
+				return j
+			}
+			braces--
+		}
+	}
+	return -1
+}
+
+// evalPlaceholder resolves the body of one placeholder. ok is false when the
+// placeholder should stay in the output untouched.
+func evalPlaceholder(expr string, vars map[string]any, missing *[]string) (any, bool, error) {
+	if name, fallback, hasFallback, ok := envRef(expr); ok {
+		if value, set := os.LookupEnv(name); set {
+			return value, true, nil
+		}
+		if !hasFallback {
+			return nil, false, fmt.Errorf("environment variable %s is not set", name)
+		}
+		value, err := ExpandString(fallback, vars)
+		return value, err == nil, err
+	}
+	switch {
+	case nameRE.MatchString(expr):
+		name := strings.TrimPrefix(expr, secretPrefix)
+		value, ok, err := lookupVar(name, vars)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			*missing = append(*missing, name)
+			return nil, false, nil
+		}
+		if strings.HasPrefix(expr, secretPrefix) {
+			secrets.Add(ToString(value))
+		}
+		return value, true, nil
+	case callRE.MatchString(expr):
+		p := &exprParser{src: expr, vars: vars}
+		value, err := p.parse()
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	}
+	return nil, false, nil
+}
+
+// Placeholder stands in for a value that is only known at run time, such as
+// a capture during a dry run. It expands to `${name}`, including any path
+// walked into it.
+type Placeholder string
+
+func (p Placeholder) String() string {
+	return "${" + string(p) + "}"
+}
+
+func (p Placeholder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// lookupVar resolves a variable name. A flat key wins, so a var literally
+// named `a.b` keeps working; otherwise dots and `[i]` walk into captured
+// objects and lists (`user.address.city`, `items[0].sku`). ok is false when
+// the root variable does not exist; err explains which segment is missing.
+func lookupVar(name string, vars map[string]any) (any, bool, error) {
+	if value, ok := vars[name]; ok {
+		return value, true, nil
+	}
+	segments, err := splitVarPath(name)
+	if err != nil {
+		return nil, false, err
+	}
+	current, ok := vars[segments[0]]
+	if !ok {
+		return nil, false, nil
+	}
+	if _, ok := current.(Placeholder); ok {
+		return Placeholder(name), true, nil
+	}
+	walked := segments[0]
+	for _, segment := range segments[1:] {
+		bracket := strings.HasPrefix(segment, "[")
+		switch t := current.(type) {
+		case map[string]any:
+			if bracket {
+				return nil, false, fmt.Errorf("%s is an object, not a list", walked)
+			}
+			next, ok := t[segment]
+			if !ok {
+				return nil, false, fmt.Errorf("%s has no field %q", walked, segment)
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(strings.Trim(segment, "[]"))
+			if err != nil {
+				return nil, false, fmt.Errorf("%s is a list, use %s[0] to pick an item", walked, walked)
+			}
+			if index < 0 || index >= len(t) {
+				return nil, false, fmt.Errorf("%s has %d item(s), no index %d", walked, len(t), index)
+			}
+			current = t[index]
+		default:
+			return nil, false, fmt.Errorf("%s is %s, cannot read %s", walked, describeValue(current), segment)
+		}
+		if bracket {
+			walked += segment
+		} else {
+			walked += "." + segment
+		}
+	}
+	return current, true, nil
+}
+
+// splitVarPath splits `items[0].sku` into `items`, `[0]`, `sku`.
+func splitVarPath(name string) ([]string, error) {
+	var segments []string
+	rest := name
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid variable path %q: missing ]", name)
+			}
+			if _, err := strconv.Atoi(rest[1:end]); err != nil || end == 1 {
+				return nil, fmt.Errorf("invalid variable path %q: index must be a number", name)
+			}
+			if len(segments) == 0 {
+				return nil, fmt.Errorf("invalid variable path %q", name)
+			}
+			segments = append(segments, rest[:end+1])
+			rest = rest[end+1:]
+			if strings.HasPrefix(rest, ".") {
+				rest = rest[1:]
+				if rest == "" {
+					return nil, fmt.Errorf("invalid variable path %q", name)
+				}
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid variable path %q", name)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+			if strings.HasPrefix(rest, ".") {
+				rest = rest[1:]
+				if rest == "" {
+					return nil, fmt.Errorf("invalid variable path %q", name)
+				}
+			}
+		}
+	}
+	return segments, nil
+}
+
+func describeValue(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int, int64, float64:
+		return "a number"
+	default:
+		return fmt.Sprintf("a %T", value)
+	}
+}
+
+// exprParser evaluates function calls. Arguments are quoted strings (which
+// may contain placeholders themselves), numbers, variable names or nested calls.
+// With refs set it only records the variables used and calls nothing.
+type exprParser struct {
+	src  string
+	pos  int
+	vars map[string]any
+	refs *[]string
+}
+
+func (p *exprParser) parse() (any, error) {
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.src) {
+		return nil, fmt.Errorf("unexpected %q", p.src[p.pos:])
+	}
+	return value, nil
+}
+
+func (p *exprParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("missing value")
+	}
+	c := p.src[p.pos]
+	switch {
+	case c == '\'' || c == '"':
+		return p.str()
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	case isNameChar(c):
+		start := p.pos
+		for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '(' {
+			return p.call(name)
+		}
+		if p.refs != nil {
+			*p.refs = append(*p.refs, name)
+			return nil, nil
+		}
+		value, ok, err := lookupVar(name, p.vars)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing vars: %s", name)
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", p.src[p.pos:])
+}
+
+func (p *exprParser) call(name string) (any, error) {
+	fn, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	if p.refs != nil {
+		fn = func(map[string]any, []any) (any, error) { return nil, nil }
+	}
+	p.pos++ // (
+	args := []any{}
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == ')' {
+		p.pos++
+		return fn(p.vars, args)
+	}
+	for {
+		arg, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, fmt.Errorf("missing ) in call to %s()", name)
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return fn(p.vars, args)
+		default:
+			return nil, fmt.Errorf("expected , or ) in call to %s(), got %q", name, p.src[p.pos:])
+		}
+	}
+}
+
+func (p *exprParser) str() (any, error) {
+	quote := p.src[p.pos]
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && quote == '"':
+			p.pos += 2
+			continue
+		case c == quote && quote == '\'' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'':
+			p.pos += 2
+			continue
+		case c == quote:
+			p.pos++
+			raw := p.src[start:p.pos]
+			var text string
+			if quote == '"' {
+				unquoted, err := strconv.Unquote(raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid string %s", raw)
+				}
+				text = unquoted
+			} else {
+				text = strings.ReplaceAll(raw[1:len(raw)-1], "''", "'")
+			}
+			if p.refs != nil {
+				nested, err := References(text)
+				*p.refs = append(*p.refs, nested...)
+				return nil, err
+			}
+			return ExpandString(text, p.vars)
+		}
+		p.pos++
+	}
+	return nil, fmt.Errorf("unterminated string %s", p.src[start:])
+}
+
+func (p *exprParser) number() (any, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	raw := p.src[start:p.pos]
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return int(n), nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid number %q", raw)
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == '[' || c == ']' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// ExpandAny expands placeholders in every string of value. A string that is
+// exactly one placeholder takes the type of what it refers to, so `${count}`
+// stays a number and a captured list or object is substituted whole.
 func ExpandAny(value any, vars map[string]any) (any, error) {
 	switch t := value.(type) {
 	case string:
-		return ExpandString(t, vars)
+		return expandValue(t, vars)
 	case []any:
 		out := make([]any, 0, len(t))
 		for _, item := range t {
@@ -47,8 +574,15 @@ func ExpandAny(value any, vars map[string]any) (any, error) {
 		}
 		return out, nil
 	case map[string]any:
+		// Sorted, so random functions draw in the same order every run.
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
 		out := map[string]any{}
-		for k, v := range t {
+		for _, k := range keys {
+			v := t[k]
 			expanded, err := ExpandAny(v, vars)
 			if err != nil {
 				return nil, err
@@ -61,6 +595,23 @@ func ExpandAny(value any, vars map[string]any) (any, error) {
 	}
 }
 
+func expandValue(input string, vars map[string]any) (any, error) {
+	if strings.HasPrefix(input, "${") && placeholderEnd(input, 2) == len(input)-1 {
+		var missing []string
+		value, ok, err := evalPlaceholder(input[2:len(input)-1], vars, &missing)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", input, err)
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("missing vars: %s", strings.Join(missing, ", "))
+		}
+		if ok {
+			return value, nil
+		}
+	}
+	return ExpandString(input, vars)
+}
+
 func uniqueStrings(items []string) []string {
 	seen := map[string]struct{}{}
 	out := make([]string, 0, len(items))
@@ -0,0 +1,141 @@
+package yamlmini
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const mergeKey = "<<"
+
+var (
+	anchorRE = regexp.MustCompile(`^&([A-Za-z0-9_-]+)(?:\s+(.*))?$`)
+	aliasRE  = regexp.MustCompile(`^\*([A-Za-z0-9_-]+)$`)
+)
+
+// splitAnchor separates a leading `&name` from the rest of a value.
+func splitAnchor(value string) (string, string) {
+	matches := anchorRE.FindStringSubmatch(value)
+	if matches == nil {
+		return "", value
+	}
+	return matches[1], matches[2]
+}
+
+// aliasName reports whether value is exactly `*name`. Plain scalars that only
+// start with `*` (like `*/*`) are left alone.
+func aliasName(value string) (string, bool) {
+	matches := aliasRE.FindStringSubmatch(value)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+type aliasResolver struct {
+	anchors map[string]*Node
+	open    map[string]bool
+}
+
+// resolveAliases replaces alias nodes with the node their anchor refers to
+// and applies `<<` merge keys. Anchors are visible from the point they are
+// defined; an alias inside its own anchor's value is a cycle.
+func resolveAliases(root *Node) (*Node, error) {
+	r := &aliasResolver{anchors: map[string]*Node{}, open: map[string]bool{}}
+	return r.resolve(root)
+}
+
+func (r *aliasResolver) resolve(n *Node) (*Node, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.Alias != "" {
+		if r.open[n.Alias] {
+			return nil, fmt.Errorf("yaml: alias *%s at line %d refers to its own anchor (cycle)", n.Alias, n.Pos.Line)
+		}
+		target, ok := r.anchors[n.Alias]
+		if !ok {
+			return nil, fmt.Errorf("yaml: undefined alias *%s at line %d", n.Alias, n.Pos.Line)
+		}
+		return target, nil
+	}
+
+	if n.Anchor != "" {
+		r.open[n.Anchor] = true
+	}
+	switch n.Kind {
+	case MapNode:
+		for _, entry := range n.Entries {
+			resolved, err := r.resolve(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			entry.Value = resolved
+		}
+		if err := applyMerges(n); err != nil {
+			return nil, err
+		}
+	case ListNode:
+		for i, item := range n.Items {
+			resolved, err := r.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			n.Items[i] = resolved
+		}
+	}
+	if n.Anchor != "" {
+		delete(r.open, n.Anchor)
+		r.anchors[n.Anchor] = n
+	}
+	return n, nil
+}
+
+// applyMerges expands `<<: *base` and `<<: [*a, *b]`. Keys written in the map
+// itself win over merged ones; among several sources the first one wins.
+func applyMerges(n *Node) error {
+	hasMerge := false
+	explicit := map[string]bool{}
+	for _, entry := range n.Entries {
+		if entry.Merge {
+			hasMerge = true
+			continue
+		}
+		explicit[entry.Key] = true
+	}
+	if !hasMerge {
+		return nil
+	}
+
+	out := make([]*Entry, 0, len(n.Entries))
+	for _, entry := range n.Entries {
+		if !entry.Merge {
+			out = append(out, entry)
+			continue
+		}
+		var sources []*Node
+		switch entry.Value.Kind {
+		case MapNode:
+			sources = []*Node{entry.Value}
+		case ListNode:
+			sources = entry.Value.Items
+		}
+		if len(sources) == 0 {
+			return fmt.Errorf("yaml: merge key << at line %d expects a map or a list of maps", entry.Pos.Line)
+		}
+		for _, source := range sources {
+			if source.Kind != MapNode {
+				return fmt.Errorf("yaml: merge key << at line %d expects a map or a list of maps", entry.Pos.Line)
+			}
+			for _, merged := range source.Entries {
+				if explicit[merged.Key] {
+					continue
+				}
+				explicit[merged.Key] = true
+				copied := *merged
+				out = append(out, &copied)
+			}
+		}
+	}
+	n.Entries = out
+	return nil
+}
@@ -0,0 +1,128 @@
+package yamlmini
+
+import (
+	"regexp"
+	"strings"
+)
+
+var blockHeaderRE = regexp.MustCompile(`^[|>][+-]?$`)
+
+func isBlockHeader(value string) bool {
+	return blockHeaderRE.MatchString(value)
+}
+
+// blockHeader reports whether text ends with a `|` / `>` block scalar header
+// and returns the indentation its content has to exceed.
+func blockHeader(text string, indent int) (int, bool) {
+	rest := text
+	for isListItem(rest) {
+		rest = listItemText(rest)
+	}
+	parent := indent
+	if _, value, ok := splitKeyValue(rest); ok && !strings.HasPrefix(rest, "{") {
+		parent = indent + len(text) - len(rest)
+		rest = value
+	}
+	_, rest = splitAnchor(rest)
+	return parent, isBlockHeader(rest)
+}
+
+// blockScalar builds the string for a block scalar. `|` keeps line breaks,
+// `>` folds them into spaces; the chomping indicator decides what happens
+// to trailing newlines (`-` strips, `+` keeps, default keeps exactly one).
+func blockScalar(header string, rows []string) string {
+	indent := -1
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if strings.TrimSpace(row) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if indent < 0 {
+			indent = leadingSpaces(row)
+		}
+		lines = append(lines, row[min(indent, leadingSpaces(row)):])
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	body := strings.Join(lines, "\n")
+	if header[0] == '>' {
+		body = foldLines(lines)
+	}
+	switch {
+	case strings.HasSuffix(header, "-"):
+		return body
+	case strings.HasSuffix(header, "+"):
+		return body + "\n" + strings.Repeat("\n", trailing)
+	default:
+		return body + "\n"
+	}
+}
+
+// foldLines joins lines with spaces. Blank lines become line breaks and
+// more-indented lines keep theirs.
+func foldLines(lines []string) string {
+	var b strings.Builder
+	for i, current := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case current == "":
+				b.WriteByte('\n')
+			case prev == "":
+			case strings.HasPrefix(current, " ") || strings.HasPrefix(prev, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(current)
+	}
+	return b.String()
+}
+
+// canWriteBlock reports whether value survives a round trip as a `|` scalar.
+func canWriteBlock(value string) bool {
+	if !strings.Contains(value, "\n") || strings.HasPrefix(value, " ") || strings.ContainsAny(value, "\r") {
+		return false
+	}
+	for _, row := range strings.Split(strings.TrimRight(value, "\n"), "\n") {
+		if row != "" && strings.TrimSpace(row) == "" {
+			return false
+		}
+	}
+	return strings.TrimSpace(value) != ""
+}
+
+// writeBlock writes value as a `|` scalar whose rows sit at indent. comment,
+// if any, follows the header.
+func writeBlock(b *builder, value string, indent int, comment string) {
+	header := "|"
+	body := strings.TrimSuffix(value, "\n")
+	switch {
+	case !strings.HasSuffix(value, "\n"):
+		header = "|-"
+	case strings.HasSuffix(value, "\n\n"):
+		header = "|+"
+	}
+	b.WriteString(" " + header)
+	writeLineComment(b, comment)
+	for _, row := range strings.Split(body, "\n") {
+		if row != "" {
+			writeIndent(b, indent)
+			b.WriteString(row)
+		}
+		b.WriteString("\n")
+	}
+	if header == "|+" {
+		b.keptEnd = b.Len()
+	}
+}
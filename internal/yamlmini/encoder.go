@@ -7,13 +7,31 @@ import (
 	"strings"
 )
 
+// builder collects the output of Marshal.
+type builder struct {
+	strings.Builder
+	// keptEnd is the length of the output after the last `|+` block, whose
+	// trailing blank lines belong to its value.
+	keptEnd int
+}
+
+// Marshal writes value as YAML. A *Node keeps its comments, anchors, aliases
+// and inline collections; other values are written with sorted keys.
 func Marshal(value any) string {
-	var b strings.Builder
-	writeYAML(&b, normalize(value), 0)
-	return strings.TrimRight(b.String(), "\n") + "\n"
+	var b builder
+	if n, ok := value.(*Node); ok {
+		writeDocument(&b, n)
+	} else {
+		writeYAML(&b, normalize(value), 0)
+	}
+	out := b.String()
+	if b.keptEnd == len(out) {
+		return out
+	}
+	return strings.TrimRight(out, "\n") + "\n"
 }
 
-func writeYAML(b *strings.Builder, value any, indent int) {
+func writeYAML(b *builder, value any, indent int) {
 	switch t := normalize(value).(type) {
 	case map[string]any:
 		keys := make([]string, 0, len(t))
@@ -23,7 +41,7 @@ func writeYAML(b *strings.Builder, value any, indent int) {
 		sort.Strings(keys)
 		for _, key := range keys {
 			writeIndent(b, indent)
-			b.WriteString(key)
+			b.WriteString(formatKey(key))
 			b.WriteString(":")
 			writeAfterKey(b, t[key], indent)
 		}
@@ -35,12 +53,12 @@ func writeYAML(b *strings.Builder, value any, indent int) {
 		}
 	default:
 		writeIndent(b, indent)
-		b.WriteString(formatScalar(t))
+		b.WriteString(formatScalar(t, itemContext))
 		b.WriteString("\n")
 	}
 }
 
-func writeAfterKey(b *strings.Builder, value any, indent int) {
+func writeAfterKey(b *builder, value any, indent int) {
 	value = normalize(value)
 	switch t := value.(type) {
 	case map[string]any:
@@ -58,13 +76,11 @@ func writeAfterKey(b *strings.Builder, value any, indent int) {
 		b.WriteString("\n")
 		writeYAML(b, t, indent+2)
 	default:
-		b.WriteString(" ")
-		b.WriteString(formatScalar(value))
-		b.WriteString("\n")
+		writeInlineScalar(b, value, indent+2, valueContext)
 	}
 }
 
-func writeAfterListMarker(b *strings.Builder, value any, indent int) {
+func writeAfterListMarker(b *builder, value any, indent int) {
 	value = normalize(value)
 	switch t := value.(type) {
 	case map[string]any:
@@ -82,13 +98,31 @@ func writeAfterListMarker(b *strings.Builder, value any, indent int) {
 		b.WriteString("\n")
 		writeYAML(b, t, indent+2)
 	default:
-		b.WriteString(" ")
-		b.WriteString(formatScalar(value))
-		b.WriteString("\n")
+		writeInlineScalar(b, value, indent+2, itemContext)
 	}
 }
 
-func formatScalar(value any) string {
+func writeInlineScalar(b *builder, value any, blockIndent int, ctx scalarContext) {
+	if text, ok := value.(string); ok && canWriteBlock(text) {
+		writeBlock(b, text, blockIndent, "")
+		return
+	}
+	b.WriteString(" ")
+	b.WriteString(formatScalar(value, ctx))
+	b.WriteString("\n")
+}
+
+// scalarContext is where a plain scalar is written; each place has its own
+// characters that would change how the line parses.
+type scalarContext int
+
+const (
+	valueContext scalarContext = iota // after `key:`
+	itemContext                       // after `- `
+	flowContext                       // inside `{...}` or `[...]`
+)
+
+func formatScalar(value any, ctx scalarContext) string {
 	switch t := value.(type) {
 	case nil:
 		return "null"
@@ -96,7 +130,7 @@ func formatScalar(value any) string {
 		if t == "" {
 			return `""`
 		}
-		if needsQuote(t) {
+		if needsQuote(t, ctx) {
 			return strconv.Quote(t)
 		}
 		return t
@@ -105,7 +139,14 @@ func formatScalar(value any) string {
 			return "true"
 		}
 		return "false"
-	case int, int32, int64, float64, float32:
+	case float64:
+		// Keep whole floats floats: 1.0 must not come back as the int 1.
+		text := strconv.FormatFloat(t, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eEn") {
+			text += ".0"
+		}
+		return text
+	case int, int32, int64, float32:
 		return fmt.Sprintf("%v", t)
 	default:
 		return strconv.Quote(fmt.Sprintf("%v", t))
@@ -143,14 +184,47 @@ func normalize(value any) any {
 	}
 }
 
-func needsQuote(value string) bool {
-	if strings.ContainsAny(value, ":\n#[]{}") {
+// needsQuote reports whether a plain string would not read back as itself.
+func needsQuote(value string, ctx scalarContext) bool {
+	if strings.TrimSpace(value) != value || strings.ContainsAny(value, "\n\r") {
+		return true
+	}
+	if strings.Contains(value, ": ") || strings.HasSuffix(value, ":") || strings.Contains(value, " #") {
 		return true
 	}
-	return strings.TrimSpace(value) != value
+	if strings.ContainsRune("!&*|>'\"%@`{}[]#?,", rune(value[0])) || value == "-" || strings.HasPrefix(value, "- ") {
+		return true
+	}
+	switch ctx {
+	case itemContext:
+		// `- a:b` would read as a map.
+		if _, _, ok := splitKeyValue(value); ok {
+			return true
+		}
+	case flowContext:
+		// Flow indicators end a plain scalar in inline collections.
+		if strings.ContainsAny(value, ",[]{}") {
+			return true
+		}
+	}
+	parsed, err := parseValue(value)
+	return err != nil || parsed != any(value)
+}
+
+// formatKey quotes keys that would not read back as themselves, including
+// a literal `<<`, which would read back as a merge key.
+func formatKey(key string) string {
+	if key == "" || key == mergeKey || strings.TrimSpace(key) != key || strings.ContainsAny(key, "#\n\",") ||
+		strings.ContainsRune("!&*|>'%@`{}[]-?:", rune(key[0])) {
+		return strconv.Quote(key)
+	}
+	if parsed, _, ok := splitKeyValue(key + ": x"); !ok || parsed != key {
+		return strconv.Quote(key)
+	}
+	return key
 }
 
-func writeIndent(b *strings.Builder, indent int) {
+func writeIndent(b *builder, indent int) {
 	for i := 0; i < indent; i++ {
 		b.WriteByte(' ')
 	}
@@ -0,0 +1,83 @@
+package yamlmini
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value map[string]any
+	}{
+		{"literal", map[string]any{"v": "a\nb\n"}},
+		{"strip", map[string]any{"v": "a\nb"}},
+		{"keep last", map[string]any{"v": "a\nb\n\n"}},
+		{"keep before other keys", map[string]any{"a": "x\n\n\n", "b": 1}},
+		{"keep nested", map[string]any{"body": map[string]any{"text": "x\ny\n\n"}}},
+		{"keep in list", map[string]any{"items": []any{"x\n\n", "y"}}},
+		{"scalars", map[string]any{"s": "text", "n": 1, "f": 1.0, "b": true, "z": nil, "e": ""}},
+		{"merge-like key", map[string]any{"<<": 1, "a": map[string]any{"<<": "x"}}},
+		{"indicator keys", map[string]any{"}a": 1, "]b": 2, ":c": 3, "-d": 4, "?e": 5, "&f": 6, "*g": 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Marshal(tt.value)
+			got, err := Parse([]byte(out))
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", out, err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Fatalf("round trip through %q\n got %#v\nwant %#v", out, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestMarshalSourceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"literal", "v: |\n  a\n  b\n"},
+		{"strip", "v: |-\n  a\n  b\n"},
+		{"keep", "v: |+\n  a\n  b\n\n"},
+		{"keep before key", "v: |+\n  a\n\n\nw: 1\n"},
+		{"folded", "v: >\n  a\n  b\n\n  c\n"},
+		{"multi-doc", "a: |+\n  x\n\n---\nb: |-\n  y\n---\nc: >\n  z\n"},
+		{"merge", "base: &b\n  x: 1\nv:\n  <<: *b\n  y: 2\n"},
+		{"quoted merge key", "v:\n  \"<<\": 1\n  w: { \"<<\": 2 }\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := ParseSource([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("ParseSource: %v", err)
+			}
+			parts := make([]string, 0, len(docs))
+			for _, doc := range docs {
+				parts = append(parts, Marshal(doc))
+			}
+			out := strings.Join(parts, "---\n")
+
+			want := documentValues(t, tt.src)
+			if got := documentValues(t, out); !reflect.DeepEqual(got, want) {
+				t.Fatalf("round trip through %q\n got %#v\nwant %#v", out, got, want)
+			}
+		})
+	}
+}
+
+func documentValues(t *testing.T, src string) []any {
+	t.Helper()
+	docs, err := ParseDocuments([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseDocuments(%q): %v", src, err)
+	}
+	out := make([]any, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, doc.Interface())
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package yamlmini
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+type Kind int
+
+const (
+	ScalarNode Kind = iota
+	MapNode
+	ListNode
+)
+
+// Pos is a 1-based source location. File is empty unless the caller set it
+// with SetFile.
+type Pos struct {
+	File string
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	loc := strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Col)
+	if p.File != "" {
+		return p.File + ":" + loc
+	}
+	return loc
+}
+
+// Node is a parsed YAML value that remembers where it came from.
+type Node struct {
+	Kind    Kind
+	Pos     Pos
+	Value   any
+	Entries []*Entry
+	Items   []*Node
+	Anchor  string
+	Alias   string
+	// Flow marks a collection written inline as `{...}` or `[...]`;
+	// Quoted marks a scalar that was written in quotes.
+	Flow   bool
+	Quoted bool
+	// HeadComment holds the comment rows above a list item or, on a
+	// document root, above the whole document ("" is a blank line).
+	// LineComment is the comment ending a list item's line. FootComment
+	// holds the rows after the last line of a document.
+	HeadComment []string
+	LineComment string
+	FootComment []string
+}
+
+// Entry is one key of a map node. Pos points at the key itself.
+type Entry struct {
+	Key   string
+	Pos   Pos
+	Value *Node
+	// Merge marks an unquoted `<<` key; a quoted "<<" is an ordinary key.
+	Merge       bool
+	HeadComment []string
+	LineComment string
+}
+
+// Get returns the value stored under key, or nil.
+func (n *Node) Get(key string) *Node {
+	if n == nil || n.Kind != MapNode {
+		return nil
+	}
+	for _, entry := range n.Entries {
+		if entry.Key == key {
+			return entry.Value
+		}
+	}
+	return nil
+}
+
+// Interface converts the node tree into plain maps, slices and scalars.
+func (n *Node) Interface() any {
+	if n == nil {
+		return nil
+	}
+	switch n.Kind {
+	case MapNode:
+		out := make(map[string]any, len(n.Entries))
+		for _, entry := range n.Entries {
+			out[entry.Key] = entry.Value.Interface()
+		}
+		return out
+	case ListNode:
+		out := make([]any, 0, len(n.Items))
+		for _, item := range n.Items {
+			out = append(out, item.Interface())
+		}
+		return out
+	default:
+		return n.Value
+	}
+}
+
+// SetFile records file on every position in the tree.
+func (n *Node) SetFile(file string) {
+	if n == nil {
+		return
+	}
+	n.Pos.File = file
+	for _, entry := range n.Entries {
+		entry.Pos.File = file
+		entry.Value.SetFile(file)
+	}
+	for _, item := range n.Items {
+		item.SetFile(file)
+	}
+}
+
+// Positions indexes the tree by path: `tests`, `tests[2]`, `tests[2].path`.
+// Map keys resolve to the key position, list items to the item start.
+func (n *Node) Positions() map[string]Pos {
+	out := map[string]Pos{}
+	if n != nil {
+		out[""] = n.Pos
+		collectPositions(n, "", out)
+	}
+	return out
+}
+
+func collectPositions(n *Node, path string, out map[string]Pos) {
+	switch n.Kind {
+	case MapNode:
+		for _, entry := range n.Entries {
+			child := entry.Key
+			if path != "" {
+				child = path + "." + entry.Key
+			}
+			out[child] = entry.Pos
+			collectPositions(entry.Value, child, out)
+		}
+	case ListNode:
+		for i, item := range n.Items {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			out[child] = item.Pos
+			collectPositions(item, child, out)
+		}
+	}
+}
+
+// fromValue wraps a plain value (e.g. an inline collection) in nodes that all
+// share pos. Inline map keys are sorted so the result is deterministic.
+func fromValue(value any, pos Pos) *Node {
+	switch t := value.(type) {
+	case map[string]any:
+		node := &Node{Kind: MapNode, Pos: pos}
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			node.Entries = append(node.Entries, &Entry{Key: key, Pos: pos, Value: fromValue(t[key], pos)})
+		}
+		return node
+	case []any:
+		node := &Node{Kind: ListNode, Pos: pos}
+		for _, item := range t {
+			node.Items = append(node.Items, fromValue(item, pos))
+		}
+		return node
+	default:
+		return &Node{Kind: ScalarNode, Pos: pos, Value: value}
+	}
+}
+
+func (n *Node) set(key string, pos Pos, value *Node) *Entry {
+	for _, entry := range n.Entries {
+		if entry.Key == key {
+			entry.Pos = pos
+			entry.Value = value
+			return entry
+		}
+	}
+	entry := &Entry{Key: key, Pos: pos, Value: value}
+	n.Entries = append(n.Entries, entry)
+	return entry
+}
+
+// Delete removes key from a map node and returns its value, or nil.
+func (n *Node) Delete(key string) *Node {
+	if n == nil || n.Kind != MapNode {
+		return nil
+	}
+	for i, entry := range n.Entries {
+		if entry.Key == key {
+			n.Entries = append(n.Entries[:i:i], n.Entries[i+1:]...)
+			return entry.Value
+		}
+	}
+	return nil
+}
@@ -12,57 +12,189 @@ type line struct {
 	text   string
 	raw    string
 	no     int
+	// block holds the raw rows of a `|` / `>` scalar started on this line.
+	block []string
+	// marker is set for `---` and `...` document separators.
+	marker bool
+	// head holds the comment rows above this line, "" for a blank line;
+	// comment is the comment at the end of the line.
+	head    []string
+	comment string
 }
 
 // Parse reads a practical YAML subset used by ReqRes configs.
-// It supports nested maps/lists and inline [] / {} collections.
+// It supports nested maps/lists, inline [] / {} collections, `|` / `>` block
+// scalars, anchors, aliases and `<<` merge keys.
 func Parse(data []byte) (any, error) {
-	lines, err := tokenize(string(data))
+	node, err := ParseNode(data)
 	if err != nil {
 		return nil, err
 	}
-	if len(lines) == 0 {
-		return map[string]any{}, nil
+	return node.Interface(), nil
+}
+
+// ParseNode is Parse but keeps the source position of every value.
+func ParseNode(data []byte) (*Node, error) {
+	docs, err := ParseDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return &Node{Kind: MapNode, Pos: Pos{Line: 1, Col: 1}}, nil
+	case 1:
+		return docs[0], nil
+	default:
+		return nil, fmt.Errorf("yaml: expected a single document, found %d", len(docs))
+	}
+}
+
+// ParseDocuments parses a `---` separated stream. Empty documents are dropped.
+// Anchors do not carry over between documents.
+func ParseDocuments(data []byte) ([]*Node, error) {
+	return parseDocuments(data, true)
+}
+
+// ParseSource parses like ParseDocuments but keeps anchors, aliases and `<<`
+// merge keys as written, for tools that rewrite a file with Marshal.
+// Comments are kept on the nodes either way.
+func ParseSource(data []byte) ([]*Node, error) {
+	return parseDocuments(data, false)
+}
+
+func parseDocuments(data []byte, resolve bool) ([]*Node, error) {
+	lines, foot, err := tokenize(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []*Node{}
+	start := 0
+	var carried []string
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !lines[i].marker {
+			continue
+		}
+		if i > start {
+			lines[start].head = append(carried, lines[start].head...)
+			carried = nil
+			doc, err := parseDocument(lines[start:i], resolve)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+		if i < len(lines) {
+			// Comments above a `---` belong to the next document.
+			carried = append(carried, lines[i].head...)
+		}
+		start = i + 1
+	}
+	if len(docs) > 0 {
+		rows := append(carried, foot...)
+		for len(rows) > 0 && rows[len(rows)-1] == "" {
+			rows = rows[:len(rows)-1]
+		}
+		docs[len(docs)-1].FootComment = rows
 	}
+	return docs, nil
+}
 
-	startIndent := lines[0].indent
-	value, next, err := parseNode(lines, 0, startIndent)
+func parseDocument(lines []line, resolve bool) (*Node, error) {
+	// Leading comments followed by a blank line describe the whole document
+	// rather than its first key.
+	var docHead []string
+	if head := lines[0].head; len(head) > 0 {
+		for i := len(head) - 1; i >= 0; i-- {
+			if head[i] == "" {
+				docHead, lines[0].head = head[:i], head[i+1:]
+				break
+			}
+		}
+	}
+	value, next, err := parseNode(lines, 0, lines[0].indent)
 	if err != nil {
 		return nil, err
 	}
 	if next != len(lines) {
 		return nil, fmt.Errorf("yaml: trailing content after line %d", lines[next].no)
 	}
-	return value, nil
+	value.HeadComment = trimBlankComments(docHead)
+	if !resolve {
+		return value, nil
+	}
+	return resolveAliases(value)
+}
+
+// trimBlankComments drops blank rows at both ends of a comment block.
+func trimBlankComments(rows []string) []string {
+	for len(rows) > 0 && rows[0] == "" {
+		rows = rows[1:]
+	}
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
 }
 
-func tokenize(input string) ([]line, error) {
+// tokenize splits input into content lines. Comment rows and blank lines are
+// attached to the next content line; the ones after the last line are
+// returned as foot.
+func tokenize(input string) ([]line, []string, error) {
 	normalized := strings.ReplaceAll(input, "\r\n", "\n")
 	normalized = strings.TrimPrefix(normalized, "\uFEFF")
+	normalized = strings.TrimSuffix(normalized, "\n")
 	rows := strings.Split(normalized, "\n")
 
 	out := make([]line, 0, len(rows))
-	for i, row := range rows {
+	var pending []string
+	for i := 0; i < len(rows); i++ {
+		row := rows[i]
 		if strings.ContainsRune(row, '\t') {
-			return nil, fmt.Errorf("yaml: tabs are not supported (line %d)", i+1)
+			return nil, nil, fmt.Errorf("yaml: tabs are not supported (line %d)", i+1)
 		}
 		clean := stripComment(row)
+		comment := strings.TrimSpace(row[len(clean):])
 		clean = strings.TrimRight(clean, " ")
 		if strings.TrimSpace(clean) == "" {
+			if comment == "" {
+				// Runs of blank lines collapse into one.
+				if len(pending) == 0 || pending[len(pending)-1] != "" {
+					pending = append(pending, "")
+				}
+			} else {
+				pending = append(pending, comment)
+			}
 			continue
 		}
 		indent := leadingSpaces(clean)
-		out = append(out, line{
-			indent: indent,
-			text:   strings.TrimSpace(clean),
-			raw:    row,
-			no:     i + 1,
-		})
+		current := line{
+			indent:  indent,
+			text:    strings.TrimSpace(clean),
+			raw:     row,
+			no:      i + 1,
+			head:    pending,
+			comment: comment,
+		}
+		pending = nil
+		if indent == 0 && (current.text == "---" || current.text == "...") {
+			current.marker = true
+		}
+		if parent, ok := blockHeader(current.text, indent); ok {
+			// Block rows are taken verbatim: no comment stripping, tabs allowed.
+			end := i + 1
+			for end < len(rows) && (strings.TrimSpace(rows[end]) == "" || leadingSpaces(rows[end]) > parent) {
+				end++
+			}
+			current.block = rows[i+1 : end]
+			i = end - 1
+		}
+		out = append(out, current)
 	}
-	return out, nil
+	return out, pending, nil
 }
 
-func parseNode(lines []line, idx int, indent int) (any, int, error) {
+func parseNode(lines []line, idx int, indent int) (*Node, int, error) {
 	if idx >= len(lines) {
 		return nil, idx, nil
 	}
@@ -79,8 +211,8 @@ func parseNode(lines []line, idx int, indent int) (any, int, error) {
 	return parseMap(lines, idx, indent)
 }
 
-func parseMap(lines []line, idx int, indent int) (map[string]any, int, error) {
-	result := map[string]any{}
+func parseMap(lines []line, idx int, indent int) (*Node, int, error) {
+	result := &Node{Kind: MapNode, Pos: lines[idx].pos(0)}
 	for idx < len(lines) {
 		current := lines[idx]
 		if current.indent < indent {
@@ -93,37 +225,75 @@ func parseMap(lines []line, idx int, indent int) (map[string]any, int, error) {
 			break
 		}
 
-		key, valuePart, ok := splitKeyValue(current.text)
-		if !ok {
-			return nil, idx, fmt.Errorf("yaml: expected key/value at line %d", current.no)
+		next, err := parseEntry(lines, idx, current.text, 0, indent, result)
+		if err != nil {
+			return nil, idx, err
 		}
-		idx++
+		idx = next
+	}
+	return result, idx, nil
+}
 
-		if valuePart == "" {
-			if idx < len(lines) && lines[idx].indent > indent {
-				child, next, err := parseNode(lines, idx, lines[idx].indent)
-				if err != nil {
-					return nil, idx, err
-				}
-				result[key] = child
-				idx = next
-			} else {
-				result[key] = nil
-			}
-			continue
+// parseEntry parses the `key: value` that starts at offset within line idx,
+// stores it in target and returns the index of the next unconsumed line.
+// Nested blocks must be indented deeper than parentIndent.
+func parseEntry(lines []line, idx int, text string, offset int, parentIndent int, target *Node) (int, error) {
+	current := lines[idx]
+	key, valuePart, ok := splitKeyValue(text)
+	if !ok {
+		return idx, fmt.Errorf("yaml: expected key/value at line %d", current.no)
+	}
+	keyPos := current.pos(offset)
+	valuePos := current.pos(offset + len(text) - len(valuePart))
+	idx++
+
+	var entry *Entry
+	defer func() {
+		if entry == nil {
+			return
 		}
+		entry.Merge = isMergeKey(text)
+		// A key that shares its line with `- ` leaves the head comment to the item.
+		if offset == 0 {
+			entry.HeadComment = current.head
+		}
+		entry.LineComment = current.comment
+	}()
 
-		parsed, err := parseValue(valuePart)
+	anchor, rest := splitAnchor(valuePart)
+	if isBlockHeader(rest) {
+		entry = target.set(key, keyPos, &Node{Kind: ScalarNode, Pos: valuePos, Value: blockScalar(rest, current.block), Anchor: anchor})
+		return idx, nil
+	}
+	if rest == "" {
+		child, next, err := parseBlock(lines, idx, parentIndent, keyPos)
 		if err != nil {
-			return nil, idx, fmt.Errorf("yaml: %w at line %d", err, current.no)
+			return idx, err
 		}
-		result[key] = parsed
+		child.Anchor = anchor
+		entry = target.set(key, keyPos, child)
+		return next, nil
 	}
-	return result, idx, nil
+
+	child, err := parseValueNode(valuePart, valuePos)
+	if err != nil {
+		return idx, fmt.Errorf("yaml: %w at line %d", err, current.no)
+	}
+	entry = target.set(key, keyPos, child)
+	return idx, nil
 }
 
-func parseList(lines []line, idx int, indent int) ([]any, int, error) {
-	result := []any{}
+// parseBlock parses the nested block that follows a bare `key:` or `-`, or
+// returns an empty scalar at pos when nothing is nested under it.
+func parseBlock(lines []line, idx int, parentIndent int, pos Pos) (*Node, int, error) {
+	if idx < len(lines) && lines[idx].indent > parentIndent {
+		return parseNode(lines, idx, lines[idx].indent)
+	}
+	return &Node{Kind: ScalarNode, Pos: pos}, idx, nil
+}
+
+func parseList(lines []line, idx int, indent int) (*Node, int, error) {
+	result := &Node{Kind: ListNode, Pos: lines[idx].pos(0)}
 	for idx < len(lines) {
 		current := lines[idx]
 		if current.indent < indent {
@@ -137,41 +307,44 @@ func parseList(lines []line, idx int, indent int) ([]any, int, error) {
 		}
 
 		itemText := listItemText(current.text)
+		itemOffset := len(current.text) - len(itemText)
 		idx++
-		if itemText == "" {
-			if idx < len(lines) && lines[idx].indent > indent {
-				child, next, err := parseNode(lines, idx, lines[idx].indent)
-				if err != nil {
-					return nil, idx, err
-				}
-				result = append(result, child)
-				idx = next
-			} else {
-				result = append(result, nil)
+		anchor, rest := splitAnchor(itemText)
+		if isBlockHeader(rest) {
+			result.Items = append(result.Items, &Node{Kind: ScalarNode, Pos: current.pos(itemOffset), Value: blockScalar(rest, current.block), Anchor: anchor,
+				HeadComment: current.head, LineComment: current.comment})
+			continue
+		}
+		if rest == "" {
+			child, next, err := parseBlock(lines, idx, indent, current.pos(0))
+			if err != nil {
+				return nil, idx, err
 			}
+			child.Anchor = anchor
+			child.HeadComment = current.head
+			child.LineComment = current.comment
+			result.Items = append(result.Items, child)
+			idx = next
 			continue
 		}
-
-		if key, valuePart, ok := splitKeyValue(itemText); ok && !strings.HasPrefix(itemText, "{") {
-			item := map[string]any{}
-			if valuePart == "" {
-				if idx < len(lines) && lines[idx].indent > indent+2 {
-					child, next, err := parseNode(lines, idx, lines[idx].indent)
-					if err != nil {
-						return nil, idx, err
-					}
-					item[key] = child
-					idx = next
-				} else {
-					item[key] = nil
-				}
+		if anchor != "" {
+			if _, _, ok := splitKeyValue(rest); ok && !strings.HasPrefix(rest, "{") {
+				// `- &name key: value` anchors the map that starts on this line.
+				itemOffset += len(itemText) - len(rest)
+				itemText = rest
 			} else {
-				parsed, err := parseValue(valuePart)
-				if err != nil {
-					return nil, idx, fmt.Errorf("yaml: %w at line %d", err, current.no)
-				}
-				item[key] = parsed
+				anchor = ""
 			}
+		}
+
+		if _, _, ok := splitKeyValue(itemText); ok && !strings.HasPrefix(itemText, "{") {
+			item := &Node{Kind: MapNode, Pos: current.pos(itemOffset)}
+			// The first key shares the dash line; nested blocks must sit deeper than the key.
+			next, err := parseEntry(lines, idx-1, itemText, itemOffset, indent+2, item)
+			if err != nil {
+				return nil, idx, err
+			}
+			idx = next
 
 			for idx < len(lines) {
 				nextLine := lines[idx]
@@ -184,83 +357,73 @@ func parseList(lines []line, idx int, indent int) ([]any, int, error) {
 				if isListItem(nextLine.text) {
 					break
 				}
-				subKey, subValue, ok := splitKeyValue(nextLine.text)
-				if !ok {
-					return nil, idx, fmt.Errorf("yaml: expected key/value at line %d", nextLine.no)
-				}
-				idx++
-				if subValue == "" {
-					if idx < len(lines) && lines[idx].indent > nextLine.indent {
-						child, next, err := parseNode(lines, idx, lines[idx].indent)
-						if err != nil {
-							return nil, idx, err
-						}
-						item[subKey] = child
-						idx = next
-					} else {
-						item[subKey] = nil
-					}
-				} else {
-					parsed, err := parseValue(subValue)
-					if err != nil {
-						return nil, idx, fmt.Errorf("yaml: %w at line %d", err, nextLine.no)
-					}
-					item[subKey] = parsed
+				next, err := parseEntry(lines, idx, nextLine.text, 0, nextLine.indent, item)
+				if err != nil {
+					return nil, idx, err
 				}
+				idx = next
 			}
-			result = append(result, item)
+			item.Anchor = anchor
+			item.HeadComment = current.head
+			result.Items = append(result.Items, item)
 			continue
 		}
 
-		parsed, err := parseValue(itemText)
+		parsed, err := parseValueNode(itemText, current.pos(itemOffset))
 		if err != nil {
 			return nil, idx, fmt.Errorf("yaml: %w at line %d", err, current.no)
 		}
-		result = append(result, parsed)
+		parsed.HeadComment = current.head
+		parsed.LineComment = current.comment
+		result.Items = append(result.Items, parsed)
 	}
 	return result, idx, nil
 }
 
-func parseValue(raw string) (any, error) {
+// parseValueNode parses an inline value. Elements of inline collections keep
+// the position of the collection itself.
+func parseValueNode(raw string, pos Pos) (*Node, error) {
 	value := strings.TrimSpace(raw)
-	if value == "" {
-		return "", nil
-	}
-	if strings.HasPrefix(value, "{") {
-		return parseInlineMap(value)
-	}
-	if strings.HasPrefix(value, "[") {
-		return parseInlineList(value)
+	if name, ok := aliasName(value); ok {
+		return &Node{Kind: ScalarNode, Pos: pos, Alias: name}, nil
 	}
-	if strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'") {
-		return parseQuoted(value)
-	}
-
-	switch strings.ToLower(value) {
-	case "null", "~":
-		return nil, nil
-	case "true":
-		return true, nil
-	case "false":
-		return false, nil
-	}
-
-	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
-		return int(n), nil
+	if anchor, rest := splitAnchor(value); anchor != "" {
+		node, err := parseValueNode(rest, pos)
+		if err != nil {
+			return nil, err
+		}
+		node.Anchor = anchor
+		return node, nil
+	}
+	switch {
+	case strings.HasPrefix(value, "{"):
+		node, err := parseInlineMapNode(value, pos)
+		if node != nil {
+			node.Flow = true
+		}
+		return node, err
+	case strings.HasPrefix(value, "["):
+		node, err := parseInlineListNode(value, pos)
+		if node != nil {
+			node.Flow = true
+		}
+		return node, err
 	}
-	if f, err := strconv.ParseFloat(value, 64); err == nil {
-		return f, nil
+	parsed, err := parseValue(value)
+	if err != nil {
+		return nil, err
 	}
-	return value, nil
+	quoted := strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'")
+	return &Node{Kind: ScalarNode, Pos: pos, Value: parsed, Quoted: quoted}, nil
 }
 
-func parseInlineMap(raw string) (map[string]any, error) {
+func parseInlineMapNode(raw string, pos Pos) (*Node, error) {
 	trimmed := strings.TrimSpace(raw)
 	if !strings.HasSuffix(trimmed, "}") {
 		return nil, fmt.Errorf("invalid inline map %q", raw)
 	}
+	out := &Node{Kind: MapNode, Pos: pos}
 	body := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
-	out := map[string]any{}
 	if body == "" {
 		return out, nil
 	}
@@ -273,39 +436,73 @@ func parseInlineMap(raw string) (map[string]any, error) {
 		if !ok {
 			return nil, fmt.Errorf("invalid inline map item %q", part)
 		}
-		value, err := parseValue(valuePart)
+		value, err := parseValueNode(valuePart, pos)
 		if err != nil {
 			return nil, err
 		}
-		out[key] = value
+		out.set(key, pos, value).Merge = isMergeKey(part)
 	}
 	return out, nil
 }
 
-func parseInlineList(raw string) ([]any, error) {
+func parseInlineListNode(raw string, pos Pos) (*Node, error) {
 	trimmed := strings.TrimSpace(raw)
 	if !strings.HasSuffix(trimmed, "]") {
 		return nil, fmt.Errorf("invalid inline list %q", raw)
 	}
+	out := &Node{Kind: ListNode, Pos: pos}
 	body := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
 	if body == "" {
-		return []any{}, nil
+		return out, nil
 	}
 	parts, err := splitTopLevel(body, ',')
 	if err != nil {
 		return nil, err
 	}
-	out := make([]any, 0, len(parts))
 	for _, part := range parts {
-		value, err := parseValue(part)
+		value, err := parseValueNode(part, pos)
 		if err != nil {
 			return nil, err
 		}
-		out = append(out, value)
+		out.Items = append(out.Items, value)
 	}
 	return out, nil
 }
 
+func parseValue(raw string) (any, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		node, err := parseValueNode(value, Pos{})
+		if err != nil {
+			return nil, err
+		}
+		return node.Interface(), nil
+	}
+	if strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'") {
+		return parseQuoted(value)
+	}
+
+	switch strings.ToLower(value) {
+	case "null", "~":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return int(n), nil
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, nil
+	}
+	return value, nil
+}
+
 func splitTopLevel(raw string, sep rune) ([]string, error) {
 	parts := []string{}
 	start := 0
@@ -348,6 +545,13 @@ func splitTopLevel(raw string, sep rune) ([]string, error) {
 	return parts, nil
 }
 
+// isMergeKey reports whether the `key: value` text starts with an unquoted
+// `<<` key.
+func isMergeKey(text string) bool {
+	key, _, ok := splitKeyValue(text)
+	return ok && key == mergeKey && strings.HasPrefix(strings.TrimSpace(text), mergeKey)
+}
+
 func splitKeyValue(raw string) (string, string, bool) {
 	depthBraces := 0
 	depthBrackets := 0
@@ -450,6 +654,11 @@ func unquoteKey(key string) string {
 	return key
 }
 
+// pos returns the position of the character at offset within the trimmed text.
+func (l line) pos(offset int) Pos {
+	return Pos{Line: l.no, Col: l.indent + offset + 1}
+}
+
 func isListItem(text string) bool {
 	return text == "-" || strings.HasPrefix(text, "- ")
 }
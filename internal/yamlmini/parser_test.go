@@ -0,0 +1,71 @@
+package yamlmini
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want any
+	}{
+		{"scalars", "s: text\nq: \"a: b\"\nn: 3\nf: 1.5\nb: true\nz: null\n",
+			map[string]any{"s": "text", "q": "a: b", "n": 3, "f": 1.5, "b": true, "z": nil}},
+		{"nested", "a:\n  b:\n    - 1\n    - c: 2\n",
+			map[string]any{"a": map[string]any{"b": []any{1, map[string]any{"c": 2}}}}},
+		{"flow", "m: { a: 1, b: [x, y] }\n",
+			map[string]any{"m": map[string]any{"a": 1, "b": []any{"x", "y"}}}},
+		{"comments", "# head\na: 1 # line\n",
+			map[string]any{"a": 1}},
+		{"merge", "base: &b\n  x: 1\n  y: 1\nv:\n  <<: *b\n  y: 2\n",
+			map[string]any{"base": map[string]any{"x": 1, "y": 1}, "v": map[string]any{"x": 1, "y": 2}}},
+		{"quoted merge key", "v:\n  \"<<\": 1\n",
+			map[string]any{"v": map[string]any{"<<": 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"merge of scalar", "v:\n  <<: 1\n", "merge key <<"},
+		{"unknown alias", "v: *missing\n", "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Parse error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestSourceRoundTrip checks that comments and anchors written in a source
+// file survive ParseSource and Marshal unchanged.
+func TestSourceRoundTrip(t *testing.T) {
+	src := "# suite\nbase: &b\n  x: 1 # note\nv:\n  <<: *b\n  y: 2\n"
+	docs, err := ParseSource([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	if got := Marshal(docs[0]); got != src {
+		t.Fatalf("Marshal = %q, want %q", got, src)
+	}
+}
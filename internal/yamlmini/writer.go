@@ -0,0 +1,199 @@
+package yamlmini
+
+import (
+	"strconv"
+	"strings"
+)
+
+// flowWidth is the longest inline collection FitsFlow accepts.
+const flowWidth = 72
+
+func writeDocument(b *builder, n *Node) {
+	if len(n.HeadComment) > 0 {
+		writeComments(b, n.HeadComment, 0)
+		b.WriteString("\n")
+	}
+	switch {
+	case n.Kind == MapNode && len(n.Entries) > 0 && !n.Flow:
+		writeEntries(b, n.Entries, 0, false)
+	case n.Kind == ListNode && len(n.Items) > 0 && !n.Flow:
+		writeItems(b, n.Items, 0)
+	default:
+		b.WriteString(strings.TrimPrefix(inlineNode(n, itemContext), " "))
+		b.WriteString("\n")
+	}
+	writeComments(b, n.FootComment, 0)
+}
+
+// writeEntries writes map entries at indent. With firstInline the first key
+// continues a `- ` that is already written.
+func writeEntries(b *builder, entries []*Entry, indent int, firstInline bool) {
+	for i, entry := range entries {
+		if i > 0 || !firstInline {
+			writeComments(b, entry.HeadComment, indent)
+			writeIndent(b, indent)
+		}
+		b.WriteString(entryKey(entry))
+		b.WriteString(":")
+		writeValue(b, entry.Value, indent, entry.LineComment)
+	}
+}
+
+// entryKey writes a merge key bare and quotes any other key that needs it.
+func entryKey(entry *Entry) string {
+	if entry.Merge {
+		return mergeKey
+	}
+	return formatKey(entry.Key)
+}
+
+func writeItems(b *builder, items []*Node, indent int) {
+	for _, item := range items {
+		head := item.HeadComment
+		if item.Kind == MapNode && len(item.Entries) > 0 && !item.Flow && item.Alias == "" {
+			// Comments above the first key can only go above the dash.
+			head = append(append([]string{}, head...), item.Entries[0].HeadComment...)
+		}
+		writeComments(b, head, indent)
+		writeIndent(b, indent)
+		b.WriteString("-")
+		switch {
+		case item.Alias != "" || item.Flow || isEmptyCollection(item) || item.Kind == ScalarNode:
+			writeValue(b, item, indent, item.LineComment)
+		case item.Kind == MapNode:
+			b.WriteString(" ")
+			if item.Anchor != "" {
+				b.WriteString("&" + item.Anchor + " ")
+			}
+			writeEntries(b, item.Entries, indent+2, true)
+		default:
+			writeValue(b, item, indent, item.LineComment)
+		}
+	}
+}
+
+// writeValue writes what follows `key:` or `-` on a line written at indent,
+// then any nested block.
+func writeValue(b *builder, n *Node, indent int, comment string) {
+	if n.Alias != "" {
+		b.WriteString(" *" + n.Alias)
+		writeLineComment(b, comment)
+		return
+	}
+	if n.Anchor != "" {
+		b.WriteString(" &" + n.Anchor)
+	}
+	switch {
+	case n.Kind == ScalarNode:
+		if text, ok := n.Value.(string); ok && canWriteBlock(text) {
+			writeBlock(b, text, indent+2, comment)
+			return
+		}
+		b.WriteString(inlineNode(n, valueContext))
+		writeLineComment(b, comment)
+	case n.Flow && n.canFlow() || isEmptyCollection(n):
+		b.WriteString(inlineNode(n, valueContext))
+		writeLineComment(b, comment)
+	case n.Kind == MapNode:
+		writeLineComment(b, comment)
+		writeEntries(b, n.Entries, indent+2, false)
+	default:
+		writeLineComment(b, comment)
+		writeItems(b, n.Items, indent+2)
+	}
+}
+
+// inlineNode renders n on one line with a leading space.
+func inlineNode(n *Node, ctx scalarContext) string {
+	if n.Alias != "" {
+		return " *" + n.Alias
+	}
+	switch n.Kind {
+	case MapNode:
+		if len(n.Entries) == 0 {
+			return " {}"
+		}
+		parts := make([]string, len(n.Entries))
+		for i, entry := range n.Entries {
+			parts[i] = entryKey(entry) + ":" + inlineNode(entry.Value, flowContext)
+		}
+		return " { " + strings.Join(parts, ", ") + " }"
+	case ListNode:
+		parts := make([]string, len(n.Items))
+		for i, item := range n.Items {
+			parts[i] = strings.TrimPrefix(inlineNode(item, flowContext), " ")
+		}
+		return " [" + strings.Join(parts, ", ") + "]"
+	default:
+		if text, ok := n.Value.(string); ok && n.Quoted {
+			return " " + strconv.Quote(text)
+		}
+		return " " + formatScalar(n.Value, ctx)
+	}
+}
+
+// canFlow reports whether n can be written inline without losing anything.
+func (n *Node) canFlow() bool {
+	if n.Anchor != "" || n.LineComment != "" || len(n.HeadComment) > 0 {
+		return false
+	}
+	switch n.Kind {
+	case MapNode:
+		for _, entry := range n.Entries {
+			if entry.LineComment != "" || len(entry.HeadComment) > 0 || entry.Merge || !entry.Value.canFlow() {
+				return false
+			}
+		}
+	case ListNode:
+		for _, item := range n.Items {
+			if !item.canFlow() {
+				return false
+			}
+		}
+	default:
+		if text, ok := n.Value.(string); ok && strings.ContainsAny(text, "\n\r") {
+			return false
+		}
+	}
+	return true
+}
+
+// FitsFlow reports whether n is a short collection of scalars that reads
+// well written inline, like `{ id: 1 }` or `[smoke, auth]`.
+func (n *Node) FitsFlow() bool {
+	if n.Kind == ScalarNode || n.Alias != "" || !n.canFlow() {
+		return false
+	}
+	for _, entry := range n.Entries {
+		if entry.Value.Kind != ScalarNode && entry.Value.Alias == "" {
+			return false
+		}
+	}
+	for _, item := range n.Items {
+		if item.Kind != ScalarNode && item.Alias == "" {
+			return false
+		}
+	}
+	return len(inlineNode(n, valueContext)) <= flowWidth
+}
+
+func isEmptyCollection(n *Node) bool {
+	return n.Kind == MapNode && len(n.Entries) == 0 || n.Kind == ListNode && len(n.Items) == 0
+}
+
+func writeComments(b *builder, rows []string, indent int) {
+	for _, row := range rows {
+		if row != "" {
+			writeIndent(b, indent)
+			b.WriteString(row)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeLineComment(b *builder, comment string) {
+	if comment != "" {
+		b.WriteString(" " + comment)
+	}
+	b.WriteString("\n")
+}